@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	service "github.com/daniel1302/vega-assistant/service/backup"
+)
+
+type ListArgs struct {
+	*BackupArgs
+
+	Dir string
+}
+
+var listArgs ListArgs
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List archived homes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listBackups(listArgs.Dir)
+	},
+}
+
+func init() {
+	listArgs.BackupArgs = &backupArgs
+
+	listCmd.PersistentFlags().StringVar(&listArgs.Dir, "backup-dir", service.DefaultDir(), "Directory with the archived homes")
+}
+
+func listBackups(dir string) error {
+	backups, err := service.List(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	if len(backups) < 1 {
+		fmt.Printf("No backups in %s\n", dir)
+		return nil
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Archive", "Home", "Created", "Size")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, backup := range backups {
+		tbl.AddRow(
+			backup.Path,
+			backup.Home,
+			backup.CreatedAt.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%.1f MB", float64(backup.Size)/1024/1024),
+		)
+	}
+
+	tbl.Print()
+	fmt.Println("")
+
+	return nil
+}
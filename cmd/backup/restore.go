@@ -0,0 +1,56 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	service "github.com/daniel1302/vega-assistant/service/backup"
+)
+
+type RestoreArgs struct {
+	*BackupArgs
+
+	Destination string
+	Force       bool
+}
+
+var restoreArgs RestoreArgs
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive or renamed home>",
+	Short: "Restore the home from the archive or the renamed home",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restoreBackup(restoreArgs.Logger, args[0], restoreArgs.Destination, restoreArgs.Force)
+	},
+}
+
+func init() {
+	restoreArgs.BackupArgs = &backupArgs
+
+	restoreCmd.PersistentFlags().StringVar(
+		&restoreArgs.Destination,
+		"destination",
+		"",
+		"Where the home is restored. By default it is the original path of the home",
+	)
+	restoreCmd.PersistentFlags().BoolVar(
+		&restoreArgs.Force,
+		"force",
+		false,
+		"Remove the existing destination before the home is restored",
+	)
+}
+
+func restoreBackup(logger *zap.SugaredLogger, backupPath, destination string, force bool) error {
+	restoredPath, err := service.Restore(logger, backupPath, destination, force)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	logger.Infof("Home restored in %s", restoredPath)
+
+	return nil
+}
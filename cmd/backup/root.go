@@ -0,0 +1,26 @@
+package backup
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+)
+
+type BackupArgs struct {
+	*cmd.RootArgs
+}
+
+var backupArgs BackupArgs
+
+// Root Command for the backups of the node homes
+var RootCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage backups of the homes replaced by the setup commands",
+}
+
+func init() {
+	backupArgs.RootArgs = &cmd.Args
+
+	RootCmd.AddCommand(listCmd)
+	RootCmd.AddCommand(restoreCmd)
+}
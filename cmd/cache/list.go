@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/github"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached releases",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listCache()
+	},
+}
+
+func listCache() error {
+	releases, err := github.CachedReleases()
+	if err != nil {
+		return fmt.Errorf("failed to list cached releases: %w", err)
+	}
+
+	if len(releases) < 1 {
+		fmt.Printf("No cached releases in %s\n", github.ArtifactsCacheRoot())
+		return nil
+	}
+
+	printReleases(releases)
+
+	return nil
+}
+
+func printReleases(releases []github.CachedRelease) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Repository", "Version", "Platform", "Artifacts", "Size")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, release := range releases {
+		tbl.AddRow(
+			release.Repository,
+			release.Version,
+			release.Platform,
+			strings.Join(release.Artifacts, ", "),
+			fmt.Sprintf("%.1f MB", float64(release.Size)/1024/1024),
+		)
+	}
+
+	tbl.Print()
+	fmt.Println("")
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+)
+
+type PruneArgs struct {
+	*CacheArgs
+
+	KeepVersions []string
+}
+
+var pruneArgs PruneArgs
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached releases",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pruneCache(pruneArgs.Logger, pruneArgs.KeepVersions)
+	},
+}
+
+func init() {
+	pruneArgs.CacheArgs = &cacheArgs
+
+	pruneCmd.PersistentFlags().StringSliceVar(
+		&pruneArgs.KeepVersions,
+		"keep",
+		[]string{},
+		"Versions to keep in the cache, e.g: --keep v0.73.4,v0.74.0",
+	)
+}
+
+func pruneCache(logger *zap.SugaredLogger, keepVersions []string) error {
+	removed, err := github.PruneCache(keepVersions)
+	if err != nil {
+		return fmt.Errorf("failed to prune the artifacts cache: %w", err)
+	}
+
+	if len(removed) < 1 {
+		logger.Info("Nothing to remove from the artifacts cache")
+		return nil
+	}
+
+	logger.Infof("Removed %d cached releases", len(removed))
+	printReleases(removed)
+
+	return nil
+}
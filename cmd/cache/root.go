@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+)
+
+type CacheArgs struct {
+	*cmd.RootArgs
+}
+
+var cacheArgs CacheArgs
+
+// Root Command for the artifacts cache
+var RootCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached vega and visor binaries",
+}
+
+func init() {
+	cacheArgs.RootArgs = &cmd.Args
+
+	RootCmd.AddCommand(listCmd)
+	RootCmd.AddCommand(pruneCmd)
+}
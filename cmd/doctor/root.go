@@ -0,0 +1,55 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+	service "github.com/daniel1302/vega-assistant/service/doctor"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+type DoctorArgs struct {
+	*cmd.RootArgs
+
+	VisorHome string
+}
+
+var doctorArgs DoctorArgs
+
+// Root Command for the node diagnostics
+var RootCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Diagnose the installed node and suggest fixes",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(doctorArgs.VisorHome)
+	},
+}
+
+func init() {
+	doctorArgs.RootArgs = &cmd.Args
+
+	RootCmd.PersistentFlags().
+		StringVar(&doctorArgs.VisorHome, "visor-home", filepath.Join(utils.CurrentUserHomePath(), "vegavisor_home"), "The vegavisor home path")
+}
+
+func runDoctor(visorHome string) error {
+	results := service.NewDoctor(visorHome).Run()
+	service.PrintResults(results)
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == service.StatusFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+)
+
+type NetworkArgs struct {
+	*cmd.RootArgs
+}
+
+var networkArgs NetworkArgs
+
+// Root Command for network definitions
+var RootCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Manage network definitions",
+}
+
+func init() {
+	networkArgs.RootArgs = &cmd.Args
+
+	RootCmd.AddCommand(updateCmd)
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	netconfig "github.com/daniel1302/vega-assistant/network"
+)
+
+type UpdateArgs struct {
+	*NetworkArgs
+
+	DescriptorURL string
+}
+
+var updateArgs UpdateArgs
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Fetch the latest network definition and save it in the local cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := updateArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return updateNetwork(updateArgs.Logger, networkConfig, updateArgs.DescriptorURL)
+	},
+}
+
+func init() {
+	updateArgs.NetworkArgs = &networkArgs
+
+	updateCmd.PersistentFlags().StringVar(
+		&updateArgs.DescriptorURL,
+		"url",
+		"",
+		"URL of the network descriptor. The descriptor-url from the network config is used when empty",
+	)
+}
+
+func updateNetwork(logger *zap.SugaredLogger, networkConfig netconfig.NetworkConfig, descriptorURL string) error {
+	if descriptorURL == "" {
+		descriptorURL = networkConfig.DescriptorURL
+	}
+
+	refreshedConfig, err := netconfig.Refresh(context.Background(), logger, networkConfig, descriptorURL)
+	if err != nil {
+		return fmt.Errorf("failed to refresh the %s network: %w", networkConfig.Name, err)
+	}
+
+	if err := netconfig.SaveCache(refreshedConfig); err != nil {
+		return fmt.Errorf("failed to save the %s network in cache: %w", networkConfig.Name, err)
+	}
+	logger.Infof("Network definition saved in %s", netconfig.CacheFilePath(refreshedConfig.Name))
+
+	printSummary(refreshedConfig)
+
+	return nil
+}
+
+func printSummary(config netconfig.NetworkConfig) {
+	fmt.Print("\n Summary:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Parameter", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("Network", config.Name)
+	tbl.AddRow("Descriptor URL", config.DescriptorURL)
+	tbl.AddRow("Data-node REST URLs", len(config.DataNodesRESTUrls))
+	tbl.AddRow("Tendermint seeds", len(config.TendermintSeeds))
+	tbl.AddRow("Tendermint RPC servers", len(config.TendermintRPCServers))
+	tbl.AddRow("Bootstrap peers", len(config.BootstrapPeers))
+
+	tbl.Print()
+	fmt.Println("")
+}
@@ -0,0 +1,91 @@
+package releases
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+)
+
+type ListArgs struct {
+	*ReleasesArgs
+
+	Limit      int
+	PreRelease bool
+}
+
+var listArgs ListArgs
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List releases and assets available for your platform",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := listArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return listReleases(networkConfig, listArgs.ResolvedGithubToken(), listArgs.Limit, listArgs.PreRelease)
+	},
+}
+
+func init() {
+	listArgs.ReleasesArgs = &releasesArgs
+
+	listCmd.PersistentFlags().IntVar(
+		&listArgs.Limit,
+		"limit",
+		20,
+		"Maximum number of listed releases",
+	)
+	listCmd.PersistentFlags().BoolVar(
+		&listArgs.PreRelease,
+		"pre-release",
+		false,
+		"Include pre-releases",
+	)
+}
+
+func listReleases(networkConfig network.NetworkConfig, githubToken string, limit int, preRelease bool) error {
+	client := github.NewReleasesClient(githubToken)
+	releases, err := client.ListReleases(context.Background(), networkConfig.Repository, limit, preRelease)
+	if err != nil {
+		return fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	fmt.Printf("\n Releases of %s:\n\n", networkConfig.Repository)
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Version", "Published", "Pre-release", fmt.Sprintf("Assets for %s/%s", runtime.GOOS, runtime.GOARCH))
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, release := range releases {
+		assets := []string{}
+		for _, asset := range release.PlatformAssets() {
+			assets = append(assets, asset.Name)
+		}
+		if len(assets) < 1 {
+			assets = append(assets, "none")
+		}
+
+		tbl.AddRow(
+			release.TagName,
+			release.PublishedAt.Format("2006-01-02"),
+			release.Prerelease,
+			strings.Join(assets, ", "),
+		)
+	}
+
+	tbl.Print()
+	fmt.Println("")
+	fmt.Println("Use the --vega-version flag with the setup command to install the selected version")
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package releases
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+)
+
+type ReleasesArgs struct {
+	*cmd.RootArgs
+}
+
+var releasesArgs ReleasesArgs
+
+// Root Command for the vega releases
+var RootCmd = &cobra.Command{
+	Use:   "releases",
+	Short: "Browse vega releases of the network repository",
+}
+
+func init() {
+	releasesArgs.RootArgs = &cmd.Args
+
+	RootCmd.AddCommand(listCmd)
+}
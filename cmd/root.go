@@ -2,13 +2,34 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/utils"
 )
 
 type RootArgs struct {
 	Logger *zap.SugaredLogger
+
+	Network           string
+	NetworkConfigFile string
+	NoNetworkCache    bool
+
+	SkipArtifactVerification bool
+	Offline                  bool
+	LocalArtifactsDir        string
+	DownloadTimeout          time.Duration
+	DownloadRetries          int
+	GithubToken              string
+	VegaVersion              string
 }
 
 var Args RootArgs
@@ -41,3 +62,155 @@ var RootCmd = &cobra.Command{
 		}
 	},
 }
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(
+		&Args.Network,
+		"network",
+		network.DefaultNetwork,
+		fmt.Sprintf("The network to setup node for. Available networks: %s", strings.Join(network.Available(), ", ")),
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&Args.NetworkConfigFile,
+		"network-config",
+		"",
+		"TOML or JSON file with the network config. It overrides the built-in network with the same name or defines a new one",
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&Args.NoNetworkCache,
+		"no-network-cache",
+		false,
+		"Do not use network definitions refreshed with the network update command, use the built-in ones",
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&Args.SkipArtifactVerification,
+		"skip-artifact-verification",
+		false,
+		"Do not verify checksums and signatures of the downloaded binaries. Verification is required only for networks with the release-checksums-file, otherwise the binaries are verified when the release publishes checksums.txt",
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&Args.Offline,
+		"offline",
+		false,
+		"Do not download binaries, install them from the artifacts cache or the --artifacts-dir directory",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&Args.LocalArtifactsDir,
+		"artifacts-dir",
+		"",
+		"Directory with the vega and visor zip files used in the offline mode. Zips are searched in <dir>/<version> and then in <dir>",
+	)
+	RootCmd.PersistentFlags().DurationVar(
+		&Args.DownloadTimeout,
+		"download-timeout",
+		utils.DefaultDownloadOptions().IdleTimeout,
+		"Abort the download attempt when no data is received for the given time",
+	)
+	RootCmd.PersistentFlags().IntVar(
+		&Args.DownloadRetries,
+		"download-retries",
+		utils.DefaultDownloadOptions().Retries,
+		"Number of download attempts. The failed download is resumed from the last received byte",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&Args.GithubToken,
+		"github-token",
+		"",
+		"Token for the GitHub API to avoid rate limits. Taken from the GITHUB_TOKEN env by default",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&Args.VegaVersion,
+		"vega-version",
+		"",
+		"Install the given vega release (e.g. pre-release or fix version) instead of the version running on the network. See the releases list command",
+	)
+}
+
+// NetworkConfig returns config for the network selected with the --network flag. The built-in
+// network is extended with the definition refreshed by the `network update` command and then
+// with the --network-config file.
+func (args RootArgs) NetworkConfig() (network.NetworkConfig, error) {
+	networkName := args.Network
+
+	var fileConfig *network.NetworkConfig
+	if args.NetworkConfigFile != "" {
+		config, err := network.LoadFromFile(args.NetworkConfigFile)
+		if err != nil {
+			return network.NetworkConfig{}, fmt.Errorf("failed to load network config file: %w", err)
+		}
+
+		if config.Name == "" {
+			config.Name = networkName
+		} else if !RootCmd.PersistentFlags().Changed("network") {
+			networkName = config.Name
+		}
+		fileConfig = &config
+	}
+
+	if !args.NoNetworkCache {
+		args.applyNetworkCache(networkName)
+	}
+
+	if fileConfig != nil {
+		if _, err := network.Extend(*fileConfig); err != nil {
+			return network.NetworkConfig{}, fmt.Errorf("failed to apply network config file %s: %w", args.NetworkConfigFile, err)
+		}
+	}
+
+	networkConfig, err := network.Get(networkName)
+	if err != nil {
+		return network.NetworkConfig{}, fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	return networkConfig, nil
+}
+
+// DownloadOptions returns settings used to get and verify binaries from the network repository
+func (args RootArgs) DownloadOptions(networkConfig network.NetworkConfig) github.DownloadOptions {
+	download := utils.DefaultDownloadOptions()
+	download.Progress = os.Stdout
+	if args.DownloadTimeout > 0 {
+		download.IdleTimeout = args.DownloadTimeout
+	}
+	if args.DownloadRetries > 0 {
+		download.Retries = args.DownloadRetries
+	}
+
+	return github.DownloadOptions{
+		Verification: github.Verification{
+			ChecksumsFile: networkConfig.ReleaseChecksumsFile,
+			PublicKey:     networkConfig.ReleasePublicKey,
+			Skip:          args.SkipArtifactVerification,
+		},
+		Offline:     args.Offline,
+		LocalDir:    args.LocalArtifactsDir,
+		Download:    download,
+		GithubToken: args.ResolvedGithubToken(),
+	}
+}
+
+// ResolvedGithubToken returns the token from the --github-token flag or the GITHUB_TOKEN env
+func (args RootArgs) ResolvedGithubToken() string {
+	if args.GithubToken != "" {
+		return args.GithubToken
+	}
+
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// applyNetworkCache extends the network with its cached definition. Built-in values are used when
+// the cache does not exist or it is invalid.
+func (args RootArgs) applyNetworkCache(networkName string) {
+	cachedConfig, err := network.LoadCache(networkName)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) && args.Logger != nil {
+			args.Logger.Infof("Could not load cached %s network definition, using built-in values: %s", networkName, err.Error())
+		}
+		return
+	}
+
+	cachedConfig.Name = networkName
+	if _, err := network.Extend(cachedConfig); err != nil && args.Logger != nil {
+		args.Logger.Infof("Cached %s network definition is invalid, using built-in values: %s", networkName, err.Error())
+	}
+}
@@ -6,70 +6,185 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tcnksm/go-input"
-	"go.uber.org/zap"
 
 	"github.com/daniel1302/vega-assistant/network"
 	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/service/node"
 	"github.com/daniel1302/vega-assistant/vegaapi"
 )
 
 type SetupDataNodeArgs struct {
 	*SetupArgs
 
-	ConfigFile string
+	Flags DataNodeSetupFlags
+}
+
+// DataNodeSetupFlags are flags shared by the data-node and full-node commands
+type DataNodeSetupFlags struct {
+	SaveConfigPath     string
+	SaveConfigPassword string
+	DryRun             bool
+	Resume             bool
+	PreserveIdentity   bool
 }
 
 var setupDataNodeArgs SetupDataNodeArgs
 
 var dataNodeCmd = &cobra.Command{
-	Use:   "data-node",
-	Short: "Prepare data-node on your computer",
+	Use:         "data-node",
+	Short:       "Prepare data-node on your computer",
+	Annotations: map[string]string{defaultConfigFileAnnotation: "config.toml"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return dataNodeSetup(setupDataNodeArgs.Logger, setupDataNodeArgs.ConfigFile)
+		networkConfig, err := setupDataNodeArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return dataNodeSetup(cmd, setupDataNodeArgs.SetupArgs, setupDataNodeArgs.Flags, networkConfig, service.NodeTypeDataNode)
 	},
 }
 
 func init() {
 	setupDataNodeArgs.SetupArgs = &setupArgs
-	dataNodeCmd.PersistentFlags().StringVar(
-		&setupDataNodeArgs.ConfigFile,
-		"config-file",
-		"config.toml",
-		"Config file to read values from. If there is an error in config file, default values are used",
+	addDataNodeSetupFlags(dataNodeCmd, &setupDataNodeArgs.Flags)
+}
+
+func addDataNodeSetupFlags(command *cobra.Command, flags *DataNodeSetupFlags) {
+	command.PersistentFlags().StringVar(
+		&flags.SaveConfigPath,
+		"save-config",
+		"",
+		"Save the final answers to the given config file. When empty, you are asked about it after the summary",
+	)
+	command.PersistentFlags().StringVar(
+		&flags.SaveConfigPassword,
+		"save-config-password",
+		string(service.SavePasswordOmit),
+		fmt.Sprintf(
+			"How to save the SQL password in the config file: %s - do not save it, %s - read it from the %s environment variable, %s - save it in plain text",
+			service.SavePasswordOmit,
+			service.SavePasswordEnv,
+			service.DefaultSQLPasswordEnv,
+			service.SavePasswordPlain,
+		),
+	)
+	command.PersistentFlags().BoolVar(
+		&flags.DryRun,
+		"dry-run",
+		false,
+		"Do all the lookups and print the plan: directories, binaries and config values, without changing anything on the disk",
+	)
+	command.PersistentFlags().BoolVar(
+		&flags.Resume,
+		"resume",
+		false,
+		"Record completed steps in the journal next to the visor home and continue the interrupted setup from it. Homes are not rolled back when the setup fails. The setup without this flag is rolled back on failure and cannot be resumed",
+	)
+	addPreserveIdentityFlag(command, &flags.PreserveIdentity)
+}
+
+func addPreserveIdentityFlag(command *cobra.Command, preserveIdentity *bool) {
+	command.PersistentFlags().BoolVar(
+		preserveIdentity,
+		"preserve-identity",
+		false,
+		"Copy the tendermint node and validator keys and the node wallets from the existing homes into the new homes, so the node keeps its peer ID and wallets",
 	)
 }
 
-func dataNodeSetup(logger *zap.SugaredLogger, configFile string) error {
+func dataNodeSetup(
+	command *cobra.Command,
+	args *SetupArgs,
+	flags DataNodeSetupFlags,
+	networkConfig network.NetworkConfig,
+	nodeType service.NodeType,
+) error {
+	logger := args.Logger
 	ui := &input.UI{
 		Writer: os.Stdout,
 		Reader: os.Stdin,
 	}
-	config, err := service.ReadGeneratorSettingsFromFile(configFile)
-	if err != nil {
-		logger.Info("Could not load config file. Using default values", zap.String("reason", err.Error()))
-
-		config = service.DefaultGenerateSettings()
+	config := service.DefaultGenerateSettings()
+	config.NodeType = nodeType
+	config.SaveConfigPath = flags.SaveConfigPath
+	config.SaveConfigPassword = service.PasswordMode(flags.SaveConfigPassword)
+	if err := args.LoadSettings(command, config); err != nil {
+		return err
+	}
+	if args.NonInteractive {
+		config.NonInteractive = true
+	}
+	if flags.PreserveIdentity {
+		config.PreserveIdentity = true
+	}
+	config.RequestedVegaVersion = args.VegaVersion
+	if config.WithDataNode() {
+		if err := config.SQLCredentials.ResolvePassword(); err != nil {
+			return err
+		}
 	}
 
-	apiClient, err := vegaapi.NewNetworkAPI(network.MainnetConfig().DataNodesRESTUrls, true, nil)
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create vega network api client: %w", err)
 	}
 
-	state := service.NewStateMachine(logger, *config)
-	if err := state.Run(apiClient, ui, network.MainnetConfig()); err != nil {
-		return fmt.Errorf("failed to generate data-node: %w", err)
+	var journal *service.Journal
+	if flags.Resume {
+		journal, err = service.LoadJournal(config.VisorHome)
+		if err != nil {
+			return err
+		}
+	}
+
+	var settings service.GenerateSettings
+	if journal != nil {
+		if journal.Settings.NodeType != nodeType {
+			return fmt.Errorf("cannot resume: the journal(%s) is for the %s setup", service.JournalPath(config.VisorHome), journal.Settings.NodeType)
+		}
+		logger.Infof("Resuming the %s setup from the journal(%s). Completed steps: %v", nodeType, service.JournalPath(config.VisorHome), journal.CompletedSteps)
+		if err := journal.ResolveSQLPassword(ui, config.SQLCredentials.Pass, config.NonInteractive); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		settings = journal.Settings
+	} else {
+		state := service.NewStateMachine(logger, *config)
+		if err := state.Run(apiClient, ui, networkConfig); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", nodeType, err)
+		}
+		settings = state.Settings
+
+		if flags.Resume {
+			journal = service.NewJournal(settings)
+		}
 	}
 
-	svc, err := service.NewDataNodeGenerator(apiClient, state.Settings, network.MainnetConfig())
+	svc, err := service.NewDataNodeGenerator(apiClient, settings, networkConfig, args.DownloadOptions(networkConfig))
 	if err != nil {
 		return fmt.Errorf("failed to start generator service: %w", err)
 	}
-	if err := svc.Run(logger); err != nil {
-		return fmt.Errorf("failed to setup data-node: %w", err)
+
+	if flags.DryRun {
+		plan, err := svc.Plan(logger)
+		if err != nil {
+			return fmt.Errorf("failed to plan %s setup: %w", nodeType, err)
+		}
+		service.PrintPlan(plan)
+
+		return nil
+	}
+
+	if journal != nil {
+		err = svc.RunWithJournal(logger, journal)
+	} else {
+		err = svc.Run(logger)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to setup %s: %w", nodeType, err)
 	}
 
-	service.PrintInstructions(state.Settings.VisorHome)
+	node.PrintRetainedIdentity(svc.RetainedIdentity())
+	service.PrintInstructions(settings.VisorHome, nodeType)
 
 	return nil
 }
@@ -0,0 +1,33 @@
+package setup
+
+import (
+	"github.com/spf13/cobra"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+)
+
+type SetupFullNodeArgs struct {
+	*SetupArgs
+
+	Flags DataNodeSetupFlags
+}
+
+var setupFullNodeArgs SetupFullNodeArgs
+
+var fullNodeCmd = &cobra.Command{
+	Use:   "full-node",
+	Short: "Prepare non-validator core node without data-node on your computer",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := setupFullNodeArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return dataNodeSetup(cmd, setupFullNodeArgs.SetupArgs, setupFullNodeArgs.Flags, networkConfig, service.NodeTypeFullNode)
+	},
+}
+
+func init() {
+	setupFullNodeArgs.SetupArgs = &setupArgs
+	addDataNodeSetupFlags(fullNodeCmd, &setupFullNodeArgs.Flags)
+}
@@ -3,16 +3,23 @@ package setup
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/tcnksm/go-input"
 	"go.uber.org/zap"
 
 	service "github.com/daniel1302/vega-assistant/service/poststart"
+	"github.com/daniel1302/vega-assistant/service/systemd"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
 )
 
 type PostStartArgs struct {
 	*SetupArgs
+
+	Settings service.ServiceSettings
 }
 
 var postStartArgs PostStartArgs
@@ -21,7 +28,7 @@ var postStartCmd = &cobra.Command{
 	Use:   "post-start",
 	Short: "Put configuration adjustments required after node has been started",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := setupPostStart(postStartArgs.Logger); err != nil {
+		if err := setupPostStart(cmd, postStartArgs.Logger); err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
@@ -30,22 +37,96 @@ var postStartCmd = &cobra.Command{
 
 func init() {
 	postStartArgs.SetupArgs = &setupArgs
+	postStartArgs.Settings = service.DefaultServiceSettings()
+
+	settings := &postStartArgs.Settings
+	postStartCmd.PersistentFlags().
+		StringVar(&settings.VegaHome, "vega-home", settings.VegaHome, "The vega home path")
+	postStartCmd.PersistentFlags().
+		StringVar(&settings.TendermintHome, "tendermint-home", settings.TendermintHome, "The tendermint home path")
+	postStartCmd.PersistentFlags().
+		BoolVar(&settings.Wait, "wait", false, "Do not ask any questions, wait until the node catches up with the network and then update configs")
+	postStartCmd.PersistentFlags().
+		StringVar(&settings.WaitSettings.CoreREST, "core-url", settings.WaitSettings.CoreREST, "REST API of the local vega core, used with --wait")
+	postStartCmd.PersistentFlags().
+		StringVar(&settings.WaitSettings.DataNodeREST, "data-node-url", settings.WaitSettings.DataNodeREST, "REST API of the local data-node, used with --wait. Ignored for node without data-node")
+	postStartCmd.PersistentFlags().
+		Uint64Var(&settings.WaitSettings.MaxBlocksLag, "max-lag", settings.WaitSettings.MaxBlocksLag, "Number of blocks the node may be behind the network to be considered synced, used with --wait")
+	postStartCmd.PersistentFlags().
+		DurationVar(&settings.WaitSettings.PollInterval, "poll-interval", settings.WaitSettings.PollInterval, "How often the node is checked, used with --wait")
+	postStartCmd.PersistentFlags().
+		DurationVar(&settings.WaitSettings.Timeout, "wait-timeout", settings.WaitSettings.Timeout, "Maximum time to wait for the node. Set 0 to wait forever, used with --wait")
+	postStartCmd.PersistentFlags().
+		BoolVar(&settings.RestartService, "restart-service", false, "Restart the vegavisor systemd service after configs are updated")
 }
 
-func setupPostStart(logger *zap.SugaredLogger) error {
-	ui := &input.UI{
-		Writer: os.Stdout,
-		Reader: os.Stdin,
+func setupPostStart(command *cobra.Command, logger *zap.SugaredLogger) error {
+	if err := postStartArgs.LoadSettings(command, &postStartArgs.Settings); err != nil {
+		return err
 	}
-	state := service.NewStateMachine()
-	err := state.Run(ui)
-	if err != nil {
-		return fmt.Errorf("failed to run state machine: %w", err)
+	if postStartArgs.NonInteractive {
+		postStartArgs.Settings.NonInteractive = true
+	}
+
+	state := service.NewStateMachine(postStartArgs.Settings)
+
+	if state.Settings.Wait {
+		if err := waitForNode(logger, state.Settings); err != nil {
+			return fmt.Errorf("failed waiting for the node: %w", err)
+		}
+	} else {
+		ui := &input.UI{
+			Writer: os.Stdout,
+			Reader: os.Stdin,
+		}
+		err := state.Run(ui)
+		if err != nil {
+			return fmt.Errorf("failed to run state machine: %w", err)
+		}
 	}
 
 	if err := service.UpdateConfig(logger, state.Settings.VegaHome, state.Settings.TendermintHome); err != nil {
 		return fmt.Errorf("failed to update configs: %s", err)
 	}
 
+	if state.Settings.RestartService {
+		if err := systemd.RestartService(logger); err != nil {
+			return fmt.Errorf("failed to apply new configs: %w", err)
+		}
+	} else {
+		logger.Info("New configs are used after the node restart")
+	}
+
+	return nil
+}
+
+func waitForNode(logger *zap.SugaredLogger, settings service.ServiceSettings) error {
+	for _, home := range []string{settings.VegaHome, settings.TendermintHome} {
+		if !utils.FileExists(home) {
+			return fmt.Errorf("%s does not exist: did you initialize your node?", home)
+		}
+	}
+
+	networkConfig, err := postStartArgs.NetworkConfig()
+	if err != nil {
+		return err
+	}
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	waitSettings := settings.WaitSettings
+	if !utils.FileExists(filepath.Join(settings.VegaHome, vegacmd.DataNodeConfigPath)) {
+		waitSettings.DataNodeREST = ""
+	}
+
+	logger.Infof("Waiting for the node to catch up with the %s network", networkConfig.Name)
+	if err := service.WaitForSync(logger, apiClient, waitSettings); err != nil {
+		return err
+	}
+	logger.Info("Node caught up with the network")
+
 	return nil
 }
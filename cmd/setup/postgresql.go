@@ -13,38 +13,124 @@ import (
 
 type PostgresqlDockerComposeArgs struct {
 	*SetupArgs
+
+	Settings service.GeneratorSettings
 }
 
 var postgresqlDockerComposeArgs PostgresqlDockerComposeArgs
 
 var postgresqlDockerComposeCmd = &cobra.Command{
 	Use:   "postgresql",
-	Short: "Prepares docker-compose.yaml file to start the postgresql server with TimescaleDB extension enabled",
+	Short: "Prepares docker-compose.yaml file to start the postgresql server with TimescaleDB extension enabled or provisions the existing server",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return setupPostgresqlDockerCompose(postgresqlDockerComposeArgs.Logger)
+		return setupPostgresqlDockerCompose(cmd, postgresqlDockerComposeArgs.Logger)
 	},
 }
 
 func init() {
 	postgresqlDockerComposeArgs.SetupArgs = &setupArgs
+	postgresqlDockerComposeArgs.Settings = service.DefaultGeneratorSettings()
+
+	settings := &postgresqlDockerComposeArgs.Settings
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.Home, "home", settings.Home, "Home for the docker-compose.yaml file")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.PostgresqlUsername, "username", settings.PostgresqlUsername, "PostgreSQL username")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.PostgresqlPassword, "password", settings.PostgresqlPassword, "PostgreSQL user password")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.PostgresqlDatabase, "database", settings.PostgresqlDatabase, "PostgreSQL database name")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		IntVar(&settings.PostgresqlPort, "port", settings.PostgresqlPort, "PostgreSQL port")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.RemoveExistingFiles, "remove-existing-file", false, "Remove the existing home in the non-interactive mode")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.DataRetention, "data-retention", settings.DataRetention, "Data retention of the data-node used to tune PostgreSQL: standard, forever, 1 day, N days, N months or N years")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		IntVar(&settings.MemoryMB, "memory-mb", 0, "Memory of the host in MB used to tune PostgreSQL. Detected when not given")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		IntVar(&settings.CPUs, "cpus", 0, "Number of CPU cores used to tune PostgreSQL. Detected when not given")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar((*string)(&settings.Output), "output", string(settings.Output), fmt.Sprintf("Generated files: %s - docker-compose.yaml with the PostgreSQL server, %s - config snippets and the bootstrap SQL script for the PostgreSQL installed from packages", service.OutputDockerCompose, service.OutputNative))
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringSliceVar(&settings.HBAAddresses, "hba-address", settings.HBAAddresses, "Addresses the data-node connects from, used for the pg_hba.conf entries in the native output")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar((*string)(&settings.Compose.Engine), "engine", string(settings.Compose.Engine), fmt.Sprintf("Compose engine the docker-compose.yaml is prepared for: %s or %s", service.ComposeEngineDocker, service.ComposeEnginePodman))
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.Compose.Image, "image", settings.Compose.Image, "PostgreSQL image with the TimescaleDB extension")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.Compose.VolumePath, "volume-path", settings.Compose.VolumePath, "Host directory for the PostgreSQL data. The named volume is used when it is not given")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.Compose.Adminer, "adminer", settings.Compose.Adminer, "Add the Adminer container available on the localhost only")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		IntVar(&settings.Compose.AdminerPort, "adminer-port", settings.Compose.AdminerPort, "Adminer port on the localhost")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.Compose.Exporter, "exporter", settings.Compose.Exporter, "Add the Prometheus postgres-exporter container")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		IntVar(&settings.Compose.ExporterPort, "exporter-port", settings.Compose.ExporterPort, "Port of the postgres-exporter metrics")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.Compose.ExporterAddress, "exporter-address", settings.Compose.ExporterAddress, "Host address the postgres-exporter port is published on. Use 0.0.0.0 to expose the metrics to other hosts")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.Start, "start", settings.Start, "Start the compose stack, wait until PostgreSQL is ready and check the TimescaleDB version")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.Existing, "existing", false, "Create the role, the database and the TimescaleDB extension on the existing server instead of preparing the docker-compose.yaml")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.PostgresqlHost, "host", settings.PostgresqlHost, "Host of the existing PostgreSQL server")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.SuperuserName, "superuser", settings.SuperuserName, "Superuser used to provision the existing server")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		StringVar(&settings.SuperuserPasswordEnv, "superuser-password-env", settings.SuperuserPasswordEnv, "Environment variable with the superuser password")
+	postgresqlDockerComposeCmd.PersistentFlags().
+		BoolVar(&settings.UpdateExisting, "update-existing", settings.UpdateExisting, "Reset the password of the existing role and change the owner of the existing database without asking")
 }
 
-func setupPostgresqlDockerCompose(logger *zap.SugaredLogger) error {
+func setupPostgresqlDockerCompose(command *cobra.Command, logger *zap.SugaredLogger) error {
+	if err := postgresqlDockerComposeArgs.LoadSettings(command, &postgresqlDockerComposeArgs.Settings); err != nil {
+		return err
+	}
+	if postgresqlDockerComposeArgs.NonInteractive {
+		postgresqlDockerComposeArgs.Settings.NonInteractive = true
+	}
+
 	ui := &input.UI{
 		Writer: os.Stdout,
 		Reader: os.Stdin,
 	}
-	state := service.NewStateMachine()
+	state := service.NewStateMachine(logger, postgresqlDockerComposeArgs.Settings)
 	err := state.Run(ui)
 	if err != nil {
 		return fmt.Errorf("failed to run state machine: %w", err)
 	}
 
-	if err := service.PrepareDockerComposeFile(logger, state.Settings); err != nil {
-		return fmt.Errorf("failed to prepare docker-compose.yaml: %w", err)
+	if state.Settings.Existing {
+		if err := service.ProvisionExistingServer(logger, ui, state.Settings); err != nil {
+			return fmt.Errorf("failed to provision existing PostgreSQL server: %w", err)
+		}
+		service.PrintReadyInstructions(state.Settings.Credentials())
+
+		return nil
+	}
+
+	if err := service.PrepareHome(logger, state.Settings, state.Tuning); err != nil {
+		return fmt.Errorf("failed to prepare PostgreSQL files: %w", err)
+	}
+
+	if state.Settings.Output == service.OutputNative {
+		service.PrintNativeInstructions(state.Settings.Home)
+
+		return nil
+	}
+
+	if state.Settings.Start {
+		if err := service.StartDockerCompose(logger, state.Settings); err != nil {
+			return fmt.Errorf("failed to start PostgreSQL: %w", err)
+		}
+		service.PrintReadyInstructions(state.Settings.Credentials())
+
+		return nil
 	}
 
-	service.PrintInstructions(state.Settings.Home)
+	service.PrintInstructions(state.Settings.Home, state.Settings.Compose)
 
 	return nil
 }
@@ -1,17 +1,28 @@
 package setup
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/daniel1302/vega-assistant/cmd"
+	"github.com/daniel1302/vega-assistant/utils"
 )
 
 type SetupArgs struct {
 	*cmd.RootArgs
+
+	ConfigFile     string
+	NonInteractive bool
 }
 
 var setupArgs SetupArgs
 
+// defaultConfigFileAnnotation is the command annotation with the config file read when the --config-file flag is not given.
+// Commands read different settings from the config file, so only the data-node setup has the default file.
+const defaultConfigFileAnnotation = "default-config-file"
+
 // Root Command for OPS
 var RootCmd = &cobra.Command{
 	Use:   "setup",
@@ -21,8 +32,75 @@ var RootCmd = &cobra.Command{
 func init() {
 	setupArgs.RootArgs = &cmd.Args
 
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.ConfigFile,
+		"config-file",
+		"",
+		"Config file to read values from. Flags take precedence over the config file. The setup data-node command reads config.toml when it exists and the flag is not given",
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&setupArgs.NonInteractive,
+		"non-interactive",
+		false,
+		"Do not ask any questions, use values from the config file and flags",
+	)
+
 	RootCmd.AddCommand(dataNodeCmd)
+	RootCmd.AddCommand(fullNodeCmd)
+	RootCmd.AddCommand(validatorCmd)
+	RootCmd.AddCommand(seedNodeCmd)
 	RootCmd.AddCommand(postgresqlDockerComposeCmd)
 	RootCmd.AddCommand(systemdCmd)
 	RootCmd.AddCommand(postStartCmd)
 }
+
+// LoadSettings fills the settings with values from the config file. Flags explicitly set
+// on the command line take precedence over the config file. The settings must contain
+// default values and all the command flags must be bound to the settings fields.
+func (args *SetupArgs) LoadSettings(command *cobra.Command, settings utils.Settings) error {
+	changedFlags := map[string]string{}
+	changedSliceFlags := map[string][]string{}
+	command.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		// The string form of the slice flags, e.g. [a,b], cannot be set back
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			changedSliceFlags[flag.Name] = sliceValue.GetSlice()
+			return
+		}
+		changedFlags[flag.Name] = flag.Value.String()
+	})
+
+	configFile := args.ConfigFile
+	// The config file given explicitly must be valid, the default one is optional
+	if !command.Flags().Changed("config-file") {
+		configFile = command.Annotations[defaultConfigFileAnnotation]
+	}
+	if configFile != "" {
+		if err := utils.LoadSettings(configFile, settings); err != nil {
+			if command.Flags().Changed("config-file") {
+				return err
+			}
+			args.Logger.Infof("Could not load config file(%s). Using default values: %s", configFile, err.Error())
+		}
+	}
+
+	for name, value := range changedFlags {
+		if err := command.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("failed to set the %s flag: %w", name, err)
+		}
+	}
+	for name, values := range changedSliceFlags {
+		sliceValue := command.Flags().Lookup(name).Value.(pflag.SliceValue)
+		if err := sliceValue.Replace(values); err != nil {
+			return fmt.Errorf("failed to set the %s flag: %w", name, err)
+		}
+	}
+
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+
+	return nil
+}
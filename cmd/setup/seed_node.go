@@ -0,0 +1,83 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/node"
+	service "github.com/daniel1302/vega-assistant/service/seednode"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type SetupSeedNodeArgs struct {
+	*SetupArgs
+
+	PreserveIdentity bool
+}
+
+var setupSeedNodeArgs SetupSeedNodeArgs
+
+var seedNodeCmd = &cobra.Command{
+	Use:   "seed-node",
+	Short: "Prepare tendermint seed node on your computer",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := setupSeedNodeArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return seedNodeSetup(cmd, &setupSeedNodeArgs, networkConfig)
+	},
+}
+
+func init() {
+	setupSeedNodeArgs.SetupArgs = &setupArgs
+	addPreserveIdentityFlag(seedNodeCmd, &setupSeedNodeArgs.PreserveIdentity)
+}
+
+func seedNodeSetup(command *cobra.Command, args *SetupSeedNodeArgs, networkConfig network.NetworkConfig) error {
+	logger := args.Logger
+	ui := &input.UI{
+		Writer: os.Stdout,
+		Reader: os.Stdin,
+	}
+	config := service.DefaultGenerateSettings()
+	if err := args.LoadSettings(command, config); err != nil {
+		return err
+	}
+	if args.NonInteractive {
+		config.NonInteractive = true
+	}
+	if args.PreserveIdentity {
+		config.PreserveIdentity = true
+	}
+	config.RequestedVegaVersion = args.VegaVersion
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	state := service.NewStateMachine(logger, *config)
+	if err := state.Run(apiClient, ui, networkConfig); err != nil {
+		return fmt.Errorf("failed to generate seed node: %w", err)
+	}
+
+	svc, err := service.NewSeedNodeGenerator(apiClient, state.Settings, networkConfig, args.DownloadOptions(networkConfig))
+	if err != nil {
+		return fmt.Errorf("failed to start generator service: %w", err)
+	}
+	seedAddress, err := svc.Run(logger)
+	if err != nil {
+		return fmt.Errorf("failed to setup seed node: %w", err)
+	}
+
+	node.PrintRetainedIdentity(svc.RetainedIdentity())
+	service.PrintInstructions(state.Settings.VisorHome, seedAddress)
+
+	return nil
+}
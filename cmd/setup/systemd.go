@@ -3,18 +3,17 @@ package setup
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
 	service "github.com/daniel1302/vega-assistant/service/systemd"
-	"github.com/daniel1302/vega-assistant/utils"
 )
 
 type SystemdArgs struct {
 	*SetupArgs
-	VisorHome string
+
+	Settings service.Settings
 }
 
 var systemdArgs SystemdArgs
@@ -23,7 +22,12 @@ var systemdCmd = &cobra.Command{
 	Use:   "systemd",
 	Short: "Prepares systemd configuration for the data-node",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := setupSystemd(systemdArgs.Logger, systemdArgs.VisorHome); err != nil {
+		if err := systemdArgs.LoadSettings(cmd, &systemdArgs.Settings); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if err := setupSystemd(systemdArgs.Logger, systemdArgs.Settings.VisorHome); err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
@@ -32,9 +36,10 @@ var systemdCmd = &cobra.Command{
 
 func init() {
 	systemdArgs.SetupArgs = &setupArgs
+	systemdArgs.Settings = service.DefaultSettings()
 
 	systemdCmd.PersistentFlags().
-		StringVar(&systemdArgs.VisorHome, "visor-home", filepath.Join(utils.CurrentUserHomePath(), "vegavisor_home"), "The vegavisor home path")
+		StringVar(&systemdArgs.Settings.VisorHome, "visor-home", systemdArgs.Settings.VisorHome, "The vegavisor home path")
 }
 
 func setupSystemd(logger *zap.SugaredLogger, visorHome string) error {
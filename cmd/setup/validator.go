@@ -0,0 +1,83 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/node"
+	service "github.com/daniel1302/vega-assistant/service/validator"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type SetupValidatorArgs struct {
+	*SetupArgs
+
+	PreserveIdentity bool
+}
+
+var setupValidatorArgs SetupValidatorArgs
+
+var validatorCmd = &cobra.Command{
+	Use:   "validator",
+	Short: "Prepare validator node on your computer",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := setupValidatorArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return validatorSetup(cmd, &setupValidatorArgs, networkConfig)
+	},
+}
+
+func init() {
+	setupValidatorArgs.SetupArgs = &setupArgs
+	addPreserveIdentityFlag(validatorCmd, &setupValidatorArgs.PreserveIdentity)
+}
+
+func validatorSetup(command *cobra.Command, args *SetupValidatorArgs, networkConfig network.NetworkConfig) error {
+	logger := args.Logger
+	ui := &input.UI{
+		Writer: os.Stdout,
+		Reader: os.Stdin,
+	}
+	config := service.DefaultGenerateSettings()
+	if err := args.LoadSettings(command, config); err != nil {
+		return err
+	}
+	if args.NonInteractive {
+		config.NonInteractive = true
+	}
+	if args.PreserveIdentity {
+		config.PreserveIdentity = true
+	}
+	config.RequestedVegaVersion = args.VegaVersion
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	state := service.NewStateMachine(logger, *config)
+	if err := state.Run(apiClient, ui, networkConfig); err != nil {
+		return fmt.Errorf("failed to generate validator: %w", err)
+	}
+
+	svc, err := service.NewValidatorGenerator(apiClient, state.Settings, networkConfig, args.DownloadOptions(networkConfig))
+	if err != nil {
+		return fmt.Errorf("failed to start generator service: %w", err)
+	}
+	nodeWallets, err := svc.Run(logger)
+	if err != nil {
+		return fmt.Errorf("failed to setup validator: %w", err)
+	}
+
+	node.PrintRetainedIdentity(svc.RetainedIdentity())
+	service.PrintInstructions(state.Settings.VisorHome, nodeWallets)
+
+	return nil
+}
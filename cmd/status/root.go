@@ -0,0 +1,94 @@
+package status
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/cmd"
+	"github.com/daniel1302/vega-assistant/network"
+	service "github.com/daniel1302/vega-assistant/service/status"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type StatusArgs struct {
+	*cmd.RootArgs
+
+	Endpoints    service.LocalEndpoints
+	SamplePeriod time.Duration
+	Output       string
+}
+
+var statusArgs StatusArgs
+
+// Root Command for the node status
+var RootCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Compare the local node with the network",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		networkConfig, err := statusArgs.NetworkConfig()
+		if err != nil {
+			return err
+		}
+
+		return printStatus(networkConfig)
+	},
+}
+
+func init() {
+	statusArgs.RootArgs = &cmd.Args
+
+	RootCmd.PersistentFlags().StringVar(
+		&statusArgs.Endpoints.CoreREST,
+		"core-url",
+		"http://localhost:3003",
+		"REST API of the local vega core",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&statusArgs.Endpoints.DataNodeREST,
+		"data-node-url",
+		"http://localhost:3008",
+		"REST API of the local data-node. Set it empty when there is no data-node",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&statusArgs.Endpoints.TendermintRPC,
+		"tendermint-url",
+		"http://localhost:26657",
+		"RPC of the local tendermint",
+	)
+	RootCmd.PersistentFlags().DurationVar(
+		&statusArgs.SamplePeriod,
+		"sample-period",
+		10*time.Second,
+		"Time between two measurements used to estimate the catch-up time. Set 0 to skip the estimation",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&statusArgs.Output,
+		"output",
+		service.OutputTable,
+		fmt.Sprintf("Output format: %s or %s", service.OutputTable, service.OutputJSON),
+	)
+}
+
+func printStatus(networkConfig network.NetworkConfig) error {
+	if statusArgs.Output != service.OutputTable && statusArgs.Output != service.OutputJSON {
+		return fmt.Errorf("invalid output format %s: expected %s or %s", statusArgs.Output, service.OutputTable, service.OutputJSON)
+	}
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	if statusArgs.Output == service.OutputTable && statusArgs.SamplePeriod > 0 {
+		statusArgs.Logger.Infof("Measuring the node for %s to estimate the catch-up time", statusArgs.SamplePeriod)
+	}
+
+	status, err := service.Collect(apiClient, networkConfig.Name, statusArgs.Endpoints, statusArgs.SamplePeriod)
+	if err != nil {
+		return fmt.Errorf("failed to collect node status: %w", err)
+	}
+
+	return service.PrintStatus(status, statusArgs.Output)
+}
@@ -1,13 +1,15 @@
 package github
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 
+	"go.uber.org/zap"
+
 	"github.com/daniel1302/vega-assistant/utils"
 )
 
@@ -18,57 +20,206 @@ const (
 	ArtifactVisor ArtifactType = "visor"
 )
 
+// DownloadOptions describes where the artifacts come from and how they are verified
+type DownloadOptions struct {
+	Verification Verification
+	// Offline disables downloading, artifacts are taken from the cache or the LocalDir only
+	Offline bool
+	// LocalDir is the directory with the artifact zips used in the offline mode. Zips are
+	// searched in the `<LocalDir>/<version>` directory first and then in the LocalDir.
+	LocalDir string
+	Download utils.DownloadOptions
+	// GithubToken is optional token for the GitHub API rate limits
+	GithubToken string
+	// Logger receives warnings about the artifacts installed without verification
+	Logger *zap.SugaredLogger
+}
+
+func (options DownloadOptions) warnf(template string, args ...interface{}) {
+	if options.Logger != nil {
+		options.Logger.Warnf(template, args...)
+	}
+}
+
+// ArtifactName returns name of the release asset for the current platform
+func ArtifactName(artifactType ArtifactType) string {
+	return fmt.Sprintf("%s-%s-%s.zip", artifactType, runtime.GOOS, runtime.GOARCH)
+}
+
+// DownloadArtifact gets the artifact from the cache, the local directory (offline mode) or the github release,
+// unzips it into the output directory and returns path to the binary
 func DownloadArtifact(
 	repository, version, outputDir string,
 	artifactType ArtifactType,
+	options DownloadOptions,
 ) (string, error) {
-	operatingSystem := runtime.GOOS
-	architecture := runtime.GOARCH
+	artifactName := ArtifactName(artifactType)
+
+	zipPath, err := cachedArtifact(repository, version, artifactName)
+	if err != nil {
+		return "", fmt.Errorf("failed to check artifacts cache: %w", err)
+	}
+
+	if zipPath == "" && options.Offline {
+		zipPath, err = localArtifact(options, version, artifactName)
+		if err != nil {
+			return "", fmt.Errorf("offline mode: %w", err)
+		}
+	}
+
+	if zipPath == "" {
+		zipPath, err = fetchArtifact(repository, version, outputDir, artifactName, options)
+		if err != nil {
+			return "", err
+		}
+	}
 
-	artifactName := fmt.Sprintf("%s-%s-%s.zip", artifactType, operatingSystem, architecture)
+	if err := utils.Unzip(zipPath, outputDir); err != nil {
+		return "", fmt.Errorf("failed to unzip downloaded artifact(%s): %w", zipPath, err)
+	}
+
+	binaryPath := filepath.Join(outputDir, string(artifactType))
+	if err := os.Chmod(binaryPath, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to change permissions mod for binary %s: %w", binaryPath, err)
+	}
 
-	artifactURL := fmt.Sprintf(
-		"https://github.com/%s/releases/download/%s/%s",
-		repository,
-		version,
-		artifactName,
-	)
+	return binaryPath, nil
+}
 
-	filePath := filepath.Join(outputDir, artifactName)
-	out, err := os.Create(filePath)
+// LocateArtifact returns where the DownloadArtifact takes the artifact from, without downloading it.
+// It is a path for the cached or local artifact and the download URL for the release asset.
+func LocateArtifact(
+	repository, version string,
+	artifactType ArtifactType,
+	options DownloadOptions,
+) (string, error) {
+	artifactName := ArtifactName(artifactType)
+
+	zipPath, err := cachedArtifact(repository, version, artifactName)
 	if err != nil {
-		return "", fmt.Errorf("failed to create local artifact file: %w", err)
+		return "", fmt.Errorf("failed to check artifacts cache: %w", err)
+	}
+	if zipPath != "" {
+		return zipPath, nil
+	}
+
+	if options.Offline {
+		zipPath, err = localArtifact(options, version, artifactName)
+		if err != nil {
+			return "", fmt.Errorf("offline mode: %w", err)
+		}
+
+		return zipPath, nil
 	}
-	defer out.Close()
 
-	resp, err := http.Get(artifactURL)
+	_, asset, err := NewReleasesClient(options.GithubToken).ReleaseAsset(context.Background(), repository, version, artifactName)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file from '%s': %w", artifactURL, err)
+		return "", fmt.Errorf("failed to find %s: %w", artifactName, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("bad http status: %s", resp.Status)
+	return asset.BrowserDownloadURL, nil
+}
+
+// fetchArtifact downloads the artifact from the github release, verifies it and stores it in the cache.
+// The download is kept in the `.partial` file until it is verified, so the interrupted download is resumed
+// in the next run. When the verification is skipped or the release publishes no checksums file, the artifact
+// ends in the output directory and it is never stored in the cache, so the later verified run does not install it.
+func fetchArtifact(repository, version, outputDir, artifactName string, options DownloadOptions) (string, error) {
+	release, asset, err := NewReleasesClient(options.GithubToken).ReleaseAsset(context.Background(), repository, version, artifactName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s: %w", artifactName, err)
+	}
+
+	downloadOptions := options.Download
+	downloadOptions.Resume = true
+
+	verification := options.Verification
+	if verification.Skip {
+		zipPath := filepath.Join(outputDir, artifactName)
+		if err := utils.DownloadFileWithOptions(asset.BrowserDownloadURL, zipPath, downloadOptions); err != nil {
+			return "", fmt.Errorf("failed to download %s: %w", artifactName, err)
+		}
+
+		return zipPath, nil
 	}
 
-	_, err = io.Copy(out, resp.Body)
+	cacheDir := ArtifactCacheDir(repository, version)
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create artifacts cache directory: %w", err)
+	}
+
+	partialPath := filepath.Join(cacheDir, artifactName+".partial")
+	if err := utils.DownloadFileWithOptions(asset.BrowserDownloadURL, partialPath, downloadOptions); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", artifactName, err)
+	}
+
+	checksums, err := ReleaseChecksums(release, options)
+	if errors.Is(err, ErrChecksumsNotPublished) {
+		options.warnf("Installing unverified %s: %s", artifactName, err.Error())
+		// Unverified artifact is never stored in the cache
+		zipPath := filepath.Join(outputDir, artifactName)
+		if err := os.Rename(partialPath, zipPath); err != nil {
+			return "", fmt.Errorf("failed to move downloaded %s to the output directory: %w", artifactName, err)
+		}
+
+		return zipPath, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf(
-			"failed to copy bytes from downloaded file(%s) to the local destination(%s): %w",
-			artifactURL,
-			filePath,
-			err,
-		)
+		return "", fmt.Errorf("failed to get checksums for the %s release: %w", version, err)
 	}
 
-	if err := utils.Unzip(filePath, outputDir); err != nil {
-		return "", fmt.Errorf("failed to unzip downloaded artifact(%s): %w", filePath, err)
+	if err := VerifyChecksum(partialPath, artifactName, checksums); err != nil {
+		// Corrupted download must not be resumed
+		os.Remove(partialPath)
+		return "", fmt.Errorf("refusing to install %s: %w", artifactName, err)
 	}
 
-	binaryPath := filepath.Join(outputDir, string(artifactType))
-	if err := os.Chmod(binaryPath, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to change permissions mod for binary %s: %w", binaryPath, err)
+	zipPath, err := storeInCache(partialPath, cacheDir, artifactName)
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s in the artifacts cache: %w", artifactName, err)
 	}
 
-	return binaryPath, nil
+	return zipPath, nil
+}
+
+// localArtifact finds the artifact in the local directory and verifies it with the checksums file
+// placed next to it. The checksums file may be missing only when the verification is not required.
+func localArtifact(options DownloadOptions, version, artifactName string) (string, error) {
+	if options.LocalDir == "" {
+		return "", fmt.Errorf("%s %s is not cached and no local artifacts directory given", artifactName, version)
+	}
+
+	for _, dir := range []string{filepath.Join(options.LocalDir, version), options.LocalDir} {
+		zipPath := filepath.Join(dir, artifactName)
+		if !utils.FileExists(zipPath) {
+			continue
+		}
+
+		if options.Verification.Skip {
+			return zipPath, nil
+		}
+
+		checksumsPath := filepath.Join(dir, options.Verification.checksumsFile())
+		content, err := os.ReadFile(checksumsPath)
+		if errors.Is(err, os.ErrNotExist) && !options.Verification.required() {
+			options.warnf("Installing unverified %s: no %s file next to it", zipPath, options.Verification.checksumsFile())
+			return zipPath, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read checksums file for %s: %w", zipPath, err)
+		}
+
+		checksums, err := parseChecksums(content)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse checksums file %s: %w", checksumsPath, err)
+		}
+
+		if err := VerifyChecksum(zipPath, artifactName, checksums); err != nil {
+			return "", fmt.Errorf("refusing to install %s: %w", zipPath, err)
+		}
+
+		return zipPath, nil
+	}
+
+	return "", fmt.Errorf("%s %s not found in the cache nor in the %s directory", artifactName, version, options.LocalDir)
 }
@@ -0,0 +1,178 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const checksumFileSuffix = ".sha256"
+
+// CachedRelease describes artifacts cached for one release on the given platform
+type CachedRelease struct {
+	Repository string
+	Version    string
+	Platform   string
+	Artifacts  []string
+	Size       int64
+	Path       string
+}
+
+// ArtifactsCacheRoot returns the root directory of the artifacts cache
+func ArtifactsCacheRoot() string {
+	return utils.CacheDirPath("artifacts")
+}
+
+// ArtifactCacheDir returns the cache directory for artifacts of the given release on the current platform
+func ArtifactCacheDir(repository, version string) string {
+	return filepath.Join(
+		ArtifactsCacheRoot(),
+		filepath.FromSlash(repository),
+		version,
+		fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH),
+	)
+}
+
+// cachedArtifact returns path to the cached artifact or an empty string when it is not cached.
+// The cached zip is used only when its content matches the hash recorded when it was stored.
+func cachedArtifact(repository, version, artifactName string) (string, error) {
+	zipPath := filepath.Join(ArtifactCacheDir(repository, version), artifactName)
+	if !utils.FileExists(zipPath) {
+		return "", nil
+	}
+
+	recordedHash, err := os.ReadFile(zipPath + checksumFileSuffix)
+	if err != nil {
+		return "", nil
+	}
+
+	actualHash, err := fileHash(zipPath)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(string(recordedHash)) != actualHash {
+		// The cached file is corrupted, it is downloaded again
+		return "", nil
+	}
+
+	return zipPath, nil
+}
+
+// storeInCache moves the verified artifact into the cache and records its hash
+func storeInCache(srcPath, cacheDir, artifactName string) (string, error) {
+	hash, err := fileHash(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	zipPath := filepath.Join(cacheDir, artifactName)
+	if err := os.Rename(srcPath, zipPath); err != nil {
+		return "", fmt.Errorf("failed to move artifact to %s: %w", zipPath, err)
+	}
+
+	if err := os.WriteFile(zipPath+checksumFileSuffix, []byte(hash), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write artifact hash: %w", err)
+	}
+
+	return zipPath, nil
+}
+
+// CachedReleases lists all the cached releases
+func CachedReleases() ([]CachedRelease, error) {
+	root := ArtifactsCacheRoot()
+	// Layout: <root>/<owner>/<repo>/<version>/<platform>/<artifact>.zip
+	platformDirs, err := filepath.Glob(filepath.Join(root, "*", "*", "*", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts cache: %w", err)
+	}
+
+	result := []CachedRelease{}
+	for _, platformDir := range platformDirs {
+		if !utils.IsDir(platformDir) {
+			continue
+		}
+
+		relativePath, err := filepath.Rel(root, platformDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path for %s: %w", platformDir, err)
+		}
+		parts := strings.Split(filepath.ToSlash(relativePath), "/")
+
+		release := CachedRelease{
+			Repository: strings.Join(parts[0:2], "/"),
+			Version:    parts[2],
+			Platform:   parts[3],
+			Path:       platformDir,
+		}
+
+		entries, err := os.ReadDir(platformDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", platformDir, err)
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get info for %s: %w", entry.Name(), err)
+			}
+
+			release.Size += info.Size()
+			if filepath.Ext(entry.Name()) == ".zip" {
+				release.Artifacts = append(release.Artifacts, entry.Name())
+			}
+		}
+
+		result = append(result, release)
+	}
+
+	return result, nil
+}
+
+// PruneCache removes cached releases except the versions to keep and returns the removed releases
+func PruneCache(keepVersions []string) ([]CachedRelease, error) {
+	releases, err := CachedReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]struct{}{}
+	for _, version := range keepVersions {
+		keep[version] = struct{}{}
+	}
+
+	removed := []CachedRelease{}
+	for _, release := range releases {
+		if _, ok := keep[release.Version]; ok {
+			continue
+		}
+
+		if err := os.RemoveAll(release.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove cached release %s: %w", release.Path, err)
+		}
+		removed = append(removed, release)
+	}
+
+	return removed, nil
+}
+
+func fileHash(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to compute checksum of %s: %w", filePath, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,186 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAPIURL     = "https://api.github.com"
+	apiRequestTimeout = 30 * time.Second
+	releasesPerPage   = 100
+)
+
+// ErrReleaseNotFound is returned when the repository has no release with the given tag
+var ErrReleaseNotFound = errors.New("release not found")
+
+type Asset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset returns the release asset with the given name
+func (r Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+
+	return Asset{}, false
+}
+
+// PlatformAssets returns assets built for the current operating system and architecture
+func (r Release) PlatformAssets() []Asset {
+	platform := fmt.Sprintf("-%s-%s", runtime.GOOS, runtime.GOARCH)
+
+	result := []Asset{}
+	for _, asset := range r.Assets {
+		if strings.Contains(asset.Name, platform) {
+			result = append(result, asset)
+		}
+	}
+
+	return result
+}
+
+// ReleasesClient lists releases and their assets with the GitHub REST API
+type ReleasesClient struct {
+	httpClient *http.Client
+	apiURL     string
+	token      string
+}
+
+// NewReleasesClient creates the client. The token is optional, it raises the API rate limits.
+func NewReleasesClient(token string) *ReleasesClient {
+	return &ReleasesClient{
+		httpClient: &http.Client{Timeout: apiRequestTimeout},
+		apiURL:     defaultAPIURL,
+		token:      token,
+	}
+}
+
+// ListReleases returns up to limit latest releases of the repository. Drafts are never returned
+// and pre-releases only when includePrerelease is true.
+func (c *ReleasesClient) ListReleases(ctx context.Context, repository string, limit int, includePrerelease bool) ([]Release, error) {
+	result := []Release{}
+
+	for page := 1; len(result) < limit; page++ {
+		releases := []Release{}
+		path := fmt.Sprintf("/repos/%s/releases?per_page=%d&page=%d", repository, releasesPerPage, page)
+		if err := c.get(ctx, path, &releases); err != nil {
+			return nil, fmt.Errorf("failed to list releases of %s: %w", repository, err)
+		}
+
+		for _, release := range releases {
+			if release.Draft || (release.Prerelease && !includePrerelease) {
+				continue
+			}
+
+			result = append(result, release)
+			if len(result) >= limit {
+				break
+			}
+		}
+
+		if len(releases) < releasesPerPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// ReleaseByTag returns release for the given version. The ErrReleaseNotFound is returned when
+// there is no such release.
+func (c *ReleasesClient) ReleaseByTag(ctx context.Context, repository, tag string) (*Release, error) {
+	release := &Release{}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/releases/tags/%s", repository, tag), release); err != nil {
+		return nil, fmt.Errorf("failed to get the %s release of %s: %w", tag, repository, err)
+	}
+
+	return release, nil
+}
+
+// ReleaseAsset returns the asset of the release with the error describing which assets exist
+// for the current platform when it is missing
+func (c *ReleasesClient) ReleaseAsset(ctx context.Context, repository, tag, assetName string) (*Release, Asset, error) {
+	release, err := c.ReleaseByTag(ctx, repository, tag)
+	if err != nil {
+		return nil, Asset{}, err
+	}
+
+	asset, ok := release.Asset(assetName)
+	if !ok {
+		available := []string{}
+		for _, platformAsset := range release.PlatformAssets() {
+			available = append(available, platformAsset.Name)
+		}
+
+		return nil, Asset{}, fmt.Errorf(
+			"the %s release of %s has no %s asset, assets available for %s/%s: [%s]",
+			tag,
+			repository,
+			assetName,
+			runtime.GOOS,
+			runtime.GOARCH,
+			strings.Join(available, ", "),
+		)
+	}
+
+	return release, asset, nil
+}
+
+func (c *ReleasesClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrReleaseNotFound
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return fmt.Errorf("github api rate limit exceeded, provide token with the --github-token flag or the GITHUB_TOKEN env")
+		}
+		return fmt.Errorf("bad http status: %s", resp.Status)
+	default:
+		return fmt.Errorf("bad http status: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", req.URL.String(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// DefaultChecksumsFile is the name of the release asset with the SHA-256 checksums of all artifacts
+const DefaultChecksumsFile = "checksums.txt"
+
+// ErrChecksumsNotPublished is returned when the optional checksums file is not published for the release
+var ErrChecksumsNotPublished = errors.New("checksums file not published")
+
+// Verification describes how the downloaded artifacts are verified before they are installed
+type Verification struct {
+	// ChecksumsFile is the release asset in the `sha256sum` format. When it is set, the verification is
+	// required. When empty, the DefaultChecksumsFile is verified only if the release publishes it.
+	ChecksumsFile string
+	// PublicKey is PEM encoded public key. When set, the `<ChecksumsFile>.sig` detached signature
+	// of the checksums file is verified with it.
+	PublicKey string
+	// Skip disables the verification completely
+	Skip bool
+}
+
+func (v Verification) checksumsFile() string {
+	if v.ChecksumsFile == "" {
+		return DefaultChecksumsFile
+	}
+
+	return v.ChecksumsFile
+}
+
+// required returns true when the network declares how its releases are verified, so the artifacts
+// without the checksums file must not be installed
+func (v Verification) required() bool {
+	return v.ChecksumsFile != "" || v.PublicKey != ""
+}
+
+// ReleaseChecksums downloads the checksums file for the release and verifies its signature if
+// the public key is configured. It returns the map of artifact name to the hex encoded SHA-256 hash.
+// The ErrChecksumsNotPublished is returned when the verification is not required and the release
+// has no checksums file.
+func ReleaseChecksums(release *Release, options DownloadOptions) (map[string]string, error) {
+	verification := options.Verification
+	checksumsFile := verification.checksumsFile()
+	if _, ok := release.Asset(checksumsFile); !ok && !verification.required() {
+		return nil, fmt.Errorf("the %s release has no %s asset: %w", release.TagName, checksumsFile, ErrChecksumsNotPublished)
+	}
+
+	content, err := downloadReleaseAsset(release, checksumsFile, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download checksums file %s: %w", checksumsFile, err)
+	}
+
+	if verification.PublicKey != "" {
+		signature, err := downloadReleaseAsset(release, checksumsFile+".sig", options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download signature of the checksums file %s: %w", checksumsFile, err)
+		}
+
+		if err := verifySignature(verification.PublicKey, content, signature); err != nil {
+			return nil, fmt.Errorf("invalid signature of the checksums file %s: %w", checksumsFile, err)
+		}
+	}
+
+	checksums, err := parseChecksums(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse checksums file %s: %w", checksumsFile, err)
+	}
+
+	return checksums, nil
+}
+
+// VerifyChecksum compares the SHA-256 hash of the file with the checksum published for the artifact
+func VerifyChecksum(filePath, artifactName string, checksums map[string]string) error {
+	expectedHash, ok := checksums[artifactName]
+	if !ok {
+		return fmt.Errorf("no checksum published for the %s artifact", artifactName)
+	}
+
+	actualHash, err := fileHash(filePath)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(expectedHash, actualHash) {
+		return fmt.Errorf(
+			"checksum mismatch for the %s artifact: expected sha256 %s, got %s",
+			artifactName,
+			expectedHash,
+			actualHash,
+		)
+	}
+
+	return nil
+}
+
+// parseChecksums reads the output of the `sha256sum` command: `<hash>  <file name>` per line
+func parseChecksums(content []byte) (map[string]string, error) {
+	result := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line '%s': expected <sha256> <file name>", line)
+		}
+
+		hash := fields[0]
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256 hash '%s' for %s", hash, fields[1])
+		}
+
+		// The binary mode in sha256sum prefixes file name with `*`
+		result[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// verifySignature checks the detached signature of the message. The signature may be raw or base64 encoded.
+// Ed25519, ECDSA and RSA (PKCS #1 v1.5) keys are supported.
+func verifySignature(publicKeyPEM string, message, signature []byte) error {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return fmt.Errorf("failed to decode PEM public key")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+
+	digest := sha256.Sum256(message)
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, message, signature) {
+			return fmt.Errorf("ed25519 signature verification failed")
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return fmt.Errorf("ecdsa signature verification failed")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("rsa signature verification failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return nil
+}
+
+// downloadReleaseAsset downloads the small release asset into the memory. Every attempt has the timeout
+// and the failed attempts are retried as configured in the download options.
+func downloadReleaseAsset(release *Release, assetName string, options DownloadOptions) ([]byte, error) {
+	asset, ok := release.Asset(assetName)
+	if !ok {
+		return nil, fmt.Errorf("the %s release has no %s asset", release.TagName, assetName)
+	}
+
+	client := &http.Client{Timeout: apiRequestTimeout}
+	var content []byte
+	err := utils.RetryRun(options.Download.Retries, options.Download.RetryDelay, func() error {
+		req, err := http.NewRequest(http.MethodGet, asset.BrowserDownloadURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		// The header is dropped by the http client when the download is redirected to other host
+		if options.GithubToken != "" {
+			req.Header.Set("Authorization", "Bearer "+options.GithubToken)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to get file from '%s': %w", asset.BrowserDownloadURL, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to get file from '%s': bad http status: %s", asset.BrowserDownloadURL, resp.Status)
+		}
+
+		content, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read file from '%s': %w", asset.BrowserDownloadURL, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
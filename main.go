@@ -5,11 +5,23 @@ import (
 	"os"
 
 	"github.com/daniel1302/vega-assistant/cmd"
+	"github.com/daniel1302/vega-assistant/cmd/backup"
+	"github.com/daniel1302/vega-assistant/cmd/cache"
+	"github.com/daniel1302/vega-assistant/cmd/doctor"
+	"github.com/daniel1302/vega-assistant/cmd/network"
+	"github.com/daniel1302/vega-assistant/cmd/releases"
 	"github.com/daniel1302/vega-assistant/cmd/setup"
+	"github.com/daniel1302/vega-assistant/cmd/status"
 )
 
 func init() {
 	cmd.RootCmd.AddCommand(setup.RootCmd)
+	cmd.RootCmd.AddCommand(network.RootCmd)
+	cmd.RootCmd.AddCommand(cache.RootCmd)
+	cmd.RootCmd.AddCommand(releases.RootCmd)
+	cmd.RootCmd.AddCommand(status.RootCmd)
+	cmd.RootCmd.AddCommand(doctor.RootCmd)
+	cmd.RootCmd.AddCommand(backup.RootCmd)
 }
 
 func main() {
@@ -0,0 +1,41 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// CacheFilePath returns path to the file with the refreshed network definition
+func CacheFilePath(name string) string {
+	return utils.CacheDirPath("networks", fmt.Sprintf("%s.toml", normalizeName(name)))
+}
+
+// LoadCache reads the network definition saved by the `network update` command.
+// The os.ErrNotExist error is returned when the network has never been updated.
+func LoadCache(name string) (NetworkConfig, error) {
+	return LoadFromFile(CacheFilePath(name))
+}
+
+// SaveCache writes the network definition to the cache file
+func SaveCache(config NetworkConfig) error {
+	cacheFilePath := CacheFilePath(config.Name)
+	if err := os.MkdirAll(filepath.Dir(cacheFilePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create network cache directory: %w", err)
+	}
+
+	content, err := toml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal network config: %w", err)
+	}
+
+	if err := os.WriteFile(cacheFilePath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write network cache file %s: %w", cacheFilePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package network
+
+import "github.com/daniel1302/vega-assistant/types"
+
+// FairgroundConfig returns config for the public Vega testnet. The network is restarted
+// from time to time, so starting from block 0 is not supported for it. Seeds are not
+// built in, the `network update` command discovers them from the tendermint rpc servers.
+func FairgroundConfig() NetworkConfig {
+	return NetworkConfig{
+		Name:          NetworkFairground,
+		Repository:    "vegaprotocol/vega",
+		GenesisURL:    "https://raw.githubusercontent.com/vegaprotocol/networks/master/fairground/genesis.json",
+		DescriptorURL: "https://raw.githubusercontent.com/vegaprotocol/networks/master/fairground/fairground.toml",
+		DataNodesRESTUrls: []string{
+			"https://api.n00.testnet.vega.rocks",
+			"https://api.n06.testnet.vega.rocks",
+			"https://api.n07.testnet.vega.rocks",
+			"https://api.n08.testnet.vega.rocks",
+			"https://api.n09.testnet.vega.rocks",
+		},
+		TendermintSeeds: []string{},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{REST: "https://api.n00.testnet.vega.rocks", Endpoint: "n00.testnet.vega.rocks:26657"},
+			{REST: "https://api.n06.testnet.vega.rocks", Endpoint: "n06.testnet.vega.rocks:26657"},
+			{REST: "https://api.n07.testnet.vega.rocks", Endpoint: "n07.testnet.vega.rocks:26657"},
+			{REST: "https://api.n08.testnet.vega.rocks", Endpoint: "n08.testnet.vega.rocks:26657"},
+			{REST: "https://api.n09.testnet.vega.rocks", Endpoint: "n09.testnet.vega.rocks:26657"},
+		},
+		BootstrapPeers:            []types.EndpointWithVegaREST{},
+		TendermintPersistentPeers: []string{},
+		BinariesOverride:          []BinaryOverride{},
+	}
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// walletNetworkConfig is the network file format used in the vegaprotocol/networks repository
+type walletNetworkConfig struct {
+	API struct {
+		REST struct {
+			Hosts []string `toml:"Hosts"`
+		} `toml:"REST"`
+	} `toml:"API"`
+}
+
+// LoadFromFile reads network config from the TOML or JSON file. Format is detected by the file extension.
+func LoadFromFile(filePath string) (NetworkConfig, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to read network config file %s: %w", filePath, err)
+	}
+
+	return parseNetworkConfig(content, filepath.Ext(filePath))
+}
+
+func parseNetworkConfig(content []byte, extension string) (NetworkConfig, error) {
+	result := NetworkConfig{}
+	switch strings.ToLower(extension) {
+	case ".json":
+		if err := json.Unmarshal(content, &result); err != nil {
+			return NetworkConfig{}, fmt.Errorf("failed to unmarshal json network config: %w", err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(content, &result); err != nil {
+			return NetworkConfig{}, fmt.Errorf("failed to unmarshal toml network config: %w", err)
+		}
+
+		// Network files from the vegaprotocol/networks repository define only the data-node hosts
+		walletConfig := walletNetworkConfig{}
+		if err := toml.Unmarshal(content, &walletConfig); err == nil && len(result.DataNodesRESTUrls) < 1 {
+			result.DataNodesRESTUrls = walletConfig.API.REST.Hosts
+		}
+	default:
+		return NetworkConfig{}, fmt.Errorf("unsupported network config extension '%s': expected .toml or .json", extension)
+	}
+
+	return result, nil
+}
+
+// Merge returns copy of the config with all non-empty fields replaced by values from the override
+func (config NetworkConfig) Merge(override NetworkConfig) NetworkConfig {
+	if override.Name != "" {
+		config.Name = override.Name
+	}
+	if override.GenesisVersion != "" {
+		config.GenesisVersion = override.GenesisVersion
+	}
+	if override.Repository != "" {
+		config.Repository = override.Repository
+	}
+	if override.GenesisURL != "" {
+		config.GenesisURL = override.GenesisURL
+	}
+	if override.DescriptorURL != "" {
+		config.DescriptorURL = override.DescriptorURL
+	}
+	if override.LowestVisorVersion != "" {
+		config.LowestVisorVersion = override.LowestVisorVersion
+	}
+	if len(override.DataNodesRESTUrls) > 0 {
+		config.DataNodesRESTUrls = override.DataNodesRESTUrls
+	}
+	if len(override.TendermintSeeds) > 0 {
+		config.TendermintSeeds = override.TendermintSeeds
+	}
+	if len(override.BootstrapPeers) > 0 {
+		config.BootstrapPeers = override.BootstrapPeers
+	}
+	if len(override.TendermintRPCServers) > 0 {
+		config.TendermintRPCServers = override.TendermintRPCServers
+	}
+	if len(override.TendermintPersistentPeers) > 0 {
+		config.TendermintPersistentPeers = override.TendermintPersistentPeers
+	}
+	if len(override.BinariesOverride) > 0 {
+		config.BinariesOverride = override.BinariesOverride
+	}
+	if override.ReleaseChecksumsFile != "" {
+		config.ReleaseChecksumsFile = override.ReleaseChecksumsFile
+	}
+	if override.ReleasePublicKey != "" {
+		config.ReleasePublicKey = override.ReleasePublicKey
+	}
+
+	return config
+}
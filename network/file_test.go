@@ -0,0 +1,169 @@
+package network
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+func TestMerge(t *testing.T) {
+	base := validTestConfig()
+	base.ReleaseChecksumsFile = "checksums.txt"
+
+	tests := []struct {
+		name     string
+		override NetworkConfig
+		expected func() NetworkConfig
+	}{
+		{
+			name:     "empty override keeps all values",
+			override: NetworkConfig{},
+			expected: func() NetworkConfig { return base },
+		},
+		{
+			name:     "string fields are replaced",
+			override: NetworkConfig{GenesisVersion: "v0.74.0", GenesisURL: "https://example.org/genesis.json", ReleasePublicKey: "key"},
+			expected: func() NetworkConfig {
+				config := base
+				config.GenesisVersion = "v0.74.0"
+				config.GenesisURL = "https://example.org/genesis.json"
+				config.ReleasePublicKey = "key"
+				return config
+			},
+		},
+		{
+			name:     "lists are replaced, not appended",
+			override: NetworkConfig{TendermintSeeds: []string{testNodeID + "@seed1.example.com:26656"}},
+			expected: func() NetworkConfig {
+				config := base
+				config.TendermintSeeds = []string{testNodeID + "@seed1.example.com:26656"}
+				return config
+			},
+		},
+		{
+			name:     "empty lists do not clear values",
+			override: NetworkConfig{TendermintSeeds: []string{}, BootstrapPeers: []types.EndpointWithVegaREST{}},
+			expected: func() NetworkConfig { return base },
+		},
+		{
+			name: "endpoint lists are replaced",
+			override: NetworkConfig{TendermintRPCServers: []types.EndpointWithVegaREST{
+				{REST: "https://api1.example.com", Endpoint: "api1.example.com:26657"},
+			}},
+			expected: func() NetworkConfig {
+				config := base
+				config.TendermintRPCServers = []types.EndpointWithVegaREST{
+					{REST: "https://api1.example.com", Endpoint: "api1.example.com:26657"},
+				}
+				return config
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := base.Merge(tt.override)
+			if expected := tt.expected(); !reflect.DeepEqual(result, expected) {
+				t.Fatalf("unexpected merge result:\n got: %+v\nwant: %+v", result, expected)
+			}
+		})
+	}
+}
+
+func TestParseNetworkConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		extension string
+		expected  NetworkConfig
+		wantErr   string
+	}{
+		{
+			name: "assistant toml config",
+			content: `
+name = "my-network"
+repository = "vegaprotocol/vega"
+data-nodes-rest-urls = ["https://api0.example.com"]
+tendermint-seeds = ["` + testNodeID + `@seed0.example.com:26656"]
+`,
+			extension: ".toml",
+			expected: NetworkConfig{
+				Name:              "my-network",
+				Repository:        "vegaprotocol/vega",
+				DataNodesRESTUrls: []string{"https://api0.example.com"},
+				TendermintSeeds:   []string{testNodeID + "@seed0.example.com:26656"},
+			},
+		},
+		{
+			name: "wallet network toml falls back to rest hosts",
+			content: `
+Name = "mainnet1"
+
+[API.REST]
+  Hosts = ["https://api0.example.com", "https://api1.example.com"]
+
+[API.GRPC]
+  Hosts = ["api0.example.com:3007"]
+`,
+			extension: ".toml",
+			expected: NetworkConfig{
+				DataNodesRESTUrls: []string{"https://api0.example.com", "https://api1.example.com"},
+			},
+		},
+		{
+			name: "assistant data-node urls win over wallet rest hosts",
+			content: `
+data-nodes-rest-urls = ["https://api0.example.com"]
+
+[API.REST]
+  Hosts = ["https://api1.example.com"]
+`,
+			extension: ".TOML",
+			expected: NetworkConfig{
+				DataNodesRESTUrls: []string{"https://api0.example.com"},
+			},
+		},
+		{
+			name:      "json config",
+			content:   `{"name": "my-network", "genesis-url": "https://example.com/genesis.json"}`,
+			extension: ".json",
+			expected: NetworkConfig{
+				Name:       "my-network",
+				GenesisURL: "https://example.com/genesis.json",
+			},
+		},
+		{
+			name:      "invalid toml",
+			content:   `name = `,
+			extension: ".toml",
+			wantErr:   "failed to unmarshal toml network config",
+		},
+		{
+			name:      "unsupported extension",
+			content:   `name: my-network`,
+			extension: ".yaml",
+			wantErr:   "unsupported network config extension '.yaml'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseNetworkConfig([]byte(tt.content), tt.extension)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Fatalf("unexpected config:\n got: %+v\nwant: %+v", result, tt.expected)
+			}
+		})
+	}
+}
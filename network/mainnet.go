@@ -1,32 +1,42 @@
 package network
 
-import "github.com/daniel1302/vega-assistant/types"
+import (
+	"github.com/daniel1302/vega-assistant/types"
+)
 
 type BinaryOverride struct {
-	OldVersion string
-	NewVersion string
-	Block      uint64
+	OldVersion string `toml:"old-version" json:"old-version"`
+	NewVersion string `toml:"new-version" json:"new-version"`
+	Block      uint64 `toml:"block" json:"block"`
 }
 
 type NetworkConfig struct {
-	GenesisVersion            string
-	Repository                string
-	GenesisURL                string
-	LowestVisorVersion        string
-	DataNodesRESTUrls         []string
-	TendermintSeeds           []string
-	BootstrapPeers            []types.EndpointWithVegaREST
-	TendermintRPCServers      []types.EndpointWithVegaREST
-	TendermintPersistentPeers []string
-	BinariesOverride          []BinaryOverride
+	Name                      string                       `toml:"name" json:"name"`
+	GenesisVersion            string                       `toml:"genesis-version" json:"genesis-version"`
+	Repository                string                       `toml:"repository" json:"repository"`
+	GenesisURL                string                       `toml:"genesis-url" json:"genesis-url"`
+	DescriptorURL             string                       `toml:"descriptor-url" json:"descriptor-url"`
+	LowestVisorVersion        string                       `toml:"lowest-visor-version" json:"lowest-visor-version"`
+	DataNodesRESTUrls         []string                     `toml:"data-nodes-rest-urls" json:"data-nodes-rest-urls"`
+	TendermintSeeds           []string                     `toml:"tendermint-seeds" json:"tendermint-seeds"`
+	BootstrapPeers            []types.EndpointWithVegaREST `toml:"bootstrap-peers" json:"bootstrap-peers"`
+	TendermintRPCServers      []types.EndpointWithVegaREST `toml:"tendermint-rpc-servers" json:"tendermint-rpc-servers"`
+	TendermintPersistentPeers []string                     `toml:"tendermint-persistent-peers" json:"tendermint-persistent-peers"`
+	BinariesOverride          []BinaryOverride             `toml:"binaries-override" json:"binaries-override"`
+	// ReleaseChecksumsFile is the release asset with SHA-256 checksums of the binaries
+	ReleaseChecksumsFile string `toml:"release-checksums-file" json:"release-checksums-file"`
+	// ReleasePublicKey is PEM encoded key used to verify the signature of the checksums file
+	ReleasePublicKey string `toml:"release-public-key" json:"release-public-key"`
 }
 
 func MainnetConfig() NetworkConfig {
 	return NetworkConfig{
+		Name:               NetworkMainnet,
 		GenesisVersion:     "v0.71.4",
 		LowestVisorVersion: "v0.73.6",
 		Repository:         "vegaprotocol/vega",
 		GenesisURL:         "https://raw.githubusercontent.com/vegaprotocol/networks/master/mainnet1/genesis.json",
+		DescriptorURL:      "https://raw.githubusercontent.com/vegaprotocol/networks/master/mainnet1/mainnet1.toml",
 		DataNodesRESTUrls: []string{
 			// "https://api0.vega.community",
 			"https://api1.vega.community",
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	NetworkMainnet    = "mainnet"
+	NetworkFairground = "fairground"
+	NetworkTestnet    = "testnet"
+
+	DefaultNetwork = NetworkMainnet
+)
+
+var registry = map[string]func() NetworkConfig{
+	NetworkMainnet:    MainnetConfig,
+	NetworkFairground: FairgroundConfig,
+	// Fairground is the public Vega testnet, so we accept both names
+	NetworkTestnet: FairgroundConfig,
+}
+
+// Register adds a new network to the registry or replaces the existing one with the same name
+func Register(config NetworkConfig) error {
+	name := normalizeName(config.Name)
+	if name == "" {
+		return fmt.Errorf("cannot register network without name")
+	}
+
+	config.Name = name
+	registry[name] = func() NetworkConfig {
+		return config
+	}
+
+	return nil
+}
+
+// Extend merges the config into the registered network with the same name, or registers
+// it as a new network. The resulting config must be valid.
+func Extend(config NetworkConfig) (NetworkConfig, error) {
+	config.Name = normalizeName(config.Name)
+	result := config
+	if base, err := Get(config.Name); err == nil {
+		result = base.Merge(config)
+	}
+
+	if err := result.Validate(); err != nil {
+		return NetworkConfig{}, fmt.Errorf("invalid config for the %s network: %w", config.Name, err)
+	}
+
+	if err := Register(result); err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to register the %s network: %w", config.Name, err)
+	}
+
+	return result, nil
+}
+
+// Get returns config for the network registered with the given name
+func Get(name string) (NetworkConfig, error) {
+	configFunc, ok := registry[normalizeName(name)]
+	if !ok {
+		return NetworkConfig{}, fmt.Errorf(
+			"unknown network %s: available networks: %s",
+			name,
+			strings.Join(Available(), ", "),
+		)
+	}
+
+	return configFunc(), nil
+}
+
+// Available returns sorted names of all registered networks
+func Available() []string {
+	result := make([]string, 0, len(registry))
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
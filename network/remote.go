@@ -0,0 +1,156 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+const fetchTimeout = 30 * time.Second
+
+// FetchDescriptor downloads the network definition. Both, the vega-assistant network config
+// and the network file from the vegaprotocol/networks repository are supported.
+func FetchDescriptor(ctx context.Context, descriptorURL string) (NetworkConfig, error) {
+	parsedURL, err := url.Parse(descriptorURL)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("invalid descriptor url %s: %w", descriptorURL, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, descriptorURL, nil)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to create request for %s: %w", descriptorURL, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to get network descriptor from %s: %w", descriptorURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return NetworkConfig{}, fmt.Errorf("failed to get network descriptor from %s: bad http status: %s", descriptorURL, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to read network descriptor response: %w", err)
+	}
+
+	result, err := parseNetworkConfig(content, path.Ext(parsedURL.Path))
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to parse network descriptor from %s: %w", descriptorURL, err)
+	}
+
+	return result, nil
+}
+
+// Refresh merges the remote network descriptor into the config and asks healthy tendermint
+// rpc servers for their node ids to get the fresh list of seeds. The discovered seeds are merged
+// into the existing ones.
+func Refresh(ctx context.Context, logger *zap.SugaredLogger, config NetworkConfig, descriptorURL string) (NetworkConfig, error) {
+	if descriptorURL == "" {
+		return NetworkConfig{}, fmt.Errorf("no descriptor url defined for the %s network", config.Name)
+	}
+
+	logger.Infof("Fetching network descriptor from %s", descriptorURL)
+	descriptor, err := FetchDescriptor(ctx, descriptorURL)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to fetch network descriptor: %w", err)
+	}
+
+	result := config.Merge(descriptor)
+	result.Name = config.Name
+	result.DescriptorURL = descriptorURL
+	logger.Infof("Network descriptor fetched. Found %d data-node REST urls", len(result.DataNodesRESTUrls))
+
+	apiClient, err := vegaapi.NewNetworkAPI(result.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	healthyRPCServers, err := apiClient.HealthyEndpoints(ctx, result.TendermintRPCServers)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to find healthy tendermint rpc servers: %w", err)
+	}
+
+	logger.Infof("Asking %d healthy tendermint rpc servers for their node ids", len(healthyRPCServers))
+	seeds, err := apiClient.TendermintPeers(ctx, healthyRPCServers)
+	switch {
+	case err != nil:
+		logger.Infof("Could not discover tendermint seeds, keeping the existing ones: %s", err.Error())
+	case len(seeds) == 0:
+		logger.Info("No tendermint seeds discovered, keeping the existing ones")
+	default:
+		logger.Infof("Discovered %d tendermint seeds", len(seeds))
+		result.TendermintSeeds = mergeSeeds(logger, result.TendermintSeeds, seeds)
+		logger.Infof("Network has %d tendermint seeds after merge", len(result.TendermintSeeds))
+	}
+
+	if err := result.Validate(); err != nil {
+		return NetworkConfig{}, fmt.Errorf("refreshed config for the %s network is invalid: %w", result.Name, err)
+	}
+
+	return result, nil
+}
+
+// mergeSeeds adds discovered seeds to the existing ones. The existing seed is replaced when it is
+// invalid or when a seed with a different node id is discovered on the same address.
+func mergeSeeds(logger *zap.SugaredLogger, existing, discovered []string) []string {
+	discoveredAddresses := map[string]struct{}{}
+	for _, seed := range discovered {
+		discoveredAddresses[seedAddress(seed)] = struct{}{}
+	}
+
+	result := []string{}
+	for _, seed := range existing {
+		if err := validateTendermintPeer(seed); err != nil {
+			logger.Infof("Replacing invalid tendermint seed: %s", err.Error())
+			continue
+		}
+
+		if _, ok := discoveredAddresses[seedAddress(seed)]; ok {
+			continue
+		}
+
+		result = append(result, seed)
+	}
+
+	return appendUnique(result, discovered...)
+}
+
+// seedAddress returns the <host>:<port> part of the `<node-id>@<host>:<port>` seed
+func seedAddress(seed string) string {
+	if _, address, found := strings.Cut(seed, "@"); found {
+		return address
+	}
+
+	return seed
+}
+
+func appendUnique(list []string, values ...string) []string {
+	seen := map[string]struct{}{}
+	result := []string{}
+
+	for _, value := range append(list, values...) {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+
+	return result
+}
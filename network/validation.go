@@ -0,0 +1,185 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/mod/semver"
+)
+
+var (
+	repositoryRegex     = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	tendermintPeerRegex = regexp.MustCompile(`^([0-9a-f]{40})@(.+)$`)
+	multiaddrRegex      = regexp.MustCompile(`^/(ip4|ip6|dns|dns4|dns6)/([^/]+)/tcp/(\d+)/(ipfs|p2p)/([^/]+)$`)
+	// libp2p peer ids are base58 encoded: legacy RSA ids start with Qm, ed25519 ids start with 12D3KooW
+	libp2pPeerIDRegex = regexp.MustCompile(`^(Qm[1-9A-HJ-NP-Za-km-z]{44}|12D3KooW[1-9A-HJ-NP-Za-km-z]{44})$`)
+)
+
+// Validate checks all the fields of the network config and returns all found problems at once
+func (config NetworkConfig) Validate() error {
+	var resErr error
+
+	if config.Name == "" {
+		resErr = multierror.Append(resErr, fmt.Errorf("name: must not be empty"))
+	}
+
+	if !repositoryRegex.MatchString(config.Repository) {
+		resErr = multierror.Append(resErr, fmt.Errorf("repository: expected <owner>/<repository>, got '%s'", config.Repository))
+	}
+
+	if config.GenesisVersion != "" && !semver.IsValid(config.GenesisVersion) {
+		resErr = multierror.Append(resErr, fmt.Errorf("genesis-version: invalid semver version '%s'", config.GenesisVersion))
+	}
+
+	if config.LowestVisorVersion != "" && !semver.IsValid(config.LowestVisorVersion) {
+		resErr = multierror.Append(resErr, fmt.Errorf("lowest-visor-version: invalid semver version '%s'", config.LowestVisorVersion))
+	}
+
+	if err := validateHTTPURL(config.GenesisURL); err != nil {
+		resErr = multierror.Append(resErr, fmt.Errorf("genesis-url: %w", err))
+	}
+
+	if config.DescriptorURL != "" {
+		if err := validateHTTPURL(config.DescriptorURL); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("descriptor-url: %w", err))
+		}
+	}
+
+	if len(config.DataNodesRESTUrls) < 1 {
+		resErr = multierror.Append(resErr, fmt.Errorf("data-nodes-rest-urls: at least one url required"))
+	}
+	for idx, restURL := range config.DataNodesRESTUrls {
+		if err := validateHTTPURL(restURL); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("data-nodes-rest-urls[%d]: %w", idx, err))
+		}
+	}
+
+	for idx, seed := range config.TendermintSeeds {
+		if err := validateTendermintPeer(seed); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("tendermint-seeds[%d]: %w", idx, err))
+		}
+	}
+
+	for idx, peer := range config.TendermintPersistentPeers {
+		if err := validateTendermintPeer(peer); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("tendermint-persistent-peers[%d]: %w", idx, err))
+		}
+	}
+
+	if len(config.TendermintRPCServers) < 1 {
+		resErr = multierror.Append(resErr, fmt.Errorf("tendermint-rpc-servers: at least one server required"))
+	}
+	for idx, rpcServer := range config.TendermintRPCServers {
+		if err := validateHTTPURL(rpcServer.REST); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("tendermint-rpc-servers[%d].rest: %w", idx, err))
+		}
+		if err := validateHostPort(rpcServer.Endpoint); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("tendermint-rpc-servers[%d].endpoint: %w", idx, err))
+		}
+	}
+
+	for idx, peer := range config.BootstrapPeers {
+		if err := validateHTTPURL(peer.REST); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("bootstrap-peers[%d].rest: %w", idx, err))
+		}
+		if err := validateMultiaddr(peer.Endpoint); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("bootstrap-peers[%d].endpoint: %w", idx, err))
+		}
+	}
+
+	for idx, override := range config.BinariesOverride {
+		if !semver.IsValid(override.OldVersion) {
+			resErr = multierror.Append(resErr, fmt.Errorf("binaries-override[%d].old-version: invalid semver version '%s'", idx, override.OldVersion))
+		}
+		if !semver.IsValid(override.NewVersion) {
+			resErr = multierror.Append(resErr, fmt.Errorf("binaries-override[%d].new-version: invalid semver version '%s'", idx, override.NewVersion))
+		}
+		if override.Block < 1 {
+			resErr = multierror.Append(resErr, fmt.Errorf("binaries-override[%d].block: must be greater than 0", idx))
+		}
+	}
+
+	return resErr
+}
+
+func validateHTTPURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url '%s': %w", rawURL, err)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("invalid url '%s': expected http or https scheme", rawURL)
+	}
+
+	if parsedURL.Hostname() == "" {
+		return fmt.Errorf("invalid url '%s': missing host", rawURL)
+	}
+
+	return nil
+}
+
+func validateHostPort(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': expected <host>:<port>: %w", address, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("invalid address '%s': missing host", address)
+	}
+
+	return validatePort(port)
+}
+
+func validatePort(port string) error {
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber < 1 || portNumber > 65535 {
+		return fmt.Errorf("invalid port '%s'", port)
+	}
+
+	return nil
+}
+
+// validateTendermintPeer checks if peer is in the <node-id>@<host>:<port> format
+func validateTendermintPeer(peer string) error {
+	matches := tendermintPeerRegex.FindStringSubmatch(peer)
+	if matches == nil {
+		return fmt.Errorf("invalid tendermint peer '%s': expected <40 hex chars node id>@<host>:<port>", peer)
+	}
+
+	return validateHostPort(matches[2])
+}
+
+// validateMultiaddr checks if address is in the /<dns|ip4|ip6>/<host>/tcp/<port>/<ipfs|p2p>/<peer-id> format
+func validateMultiaddr(address string) error {
+	matches := multiaddrRegex.FindStringSubmatch(address)
+	if matches == nil {
+		return fmt.Errorf("invalid multiaddr '%s': expected /<ip4|ip6|dns>/<host>/tcp/<port>/<ipfs|p2p>/<peer-id>", address)
+	}
+
+	switch matches[1] {
+	case "ip4":
+		if ip := net.ParseIP(matches[2]); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid multiaddr '%s': invalid ipv4 address", address)
+		}
+	case "ip6":
+		if ip := net.ParseIP(matches[2]); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("invalid multiaddr '%s': invalid ipv6 address", address)
+		}
+	}
+
+	if err := validatePort(matches[3]); err != nil {
+		return fmt.Errorf("invalid multiaddr '%s': %w", address, err)
+	}
+
+	if !libp2pPeerIDRegex.MatchString(matches[5]) {
+		return fmt.Errorf("invalid multiaddr '%s': invalid peer id '%s'", address, matches[5])
+	}
+
+	return nil
+}
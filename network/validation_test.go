@@ -0,0 +1,216 @@
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+const (
+	testNodeID = "b0db58f5651c85385f588bd5238b42bedbe57073"
+	testPeerID = "12D3KooWAHkKJfX7rt1pAuGebP9g2BGTT5w7peFGyWd2QbpyZwaw"
+)
+
+func validTestConfig() NetworkConfig {
+	return NetworkConfig{
+		Name:               "test",
+		Repository:         "vegaprotocol/vega",
+		GenesisVersion:     "v0.73.4",
+		LowestVisorVersion: "v0.73.4",
+		GenesisURL:         "https://example.com/genesis.json",
+		DataNodesRESTUrls:  []string{"https://api0.example.com"},
+		TendermintSeeds:    []string{testNodeID + "@seed0.example.com:26656"},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{REST: "https://api0.example.com", Endpoint: "api0.example.com:26657"},
+		},
+		BootstrapPeers: []types.EndpointWithVegaREST{
+			{REST: "https://api0.example.com", Endpoint: "/dns/api0.example.com/tcp/4001/ipfs/" + testPeerID},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(config *NetworkConfig)
+		wantErr string
+	}{
+		{
+			name:   "valid config",
+			modify: func(config *NetworkConfig) {},
+		},
+		{
+			name: "built-in mainnet",
+			modify: func(config *NetworkConfig) {
+				*config = MainnetConfig()
+			},
+		},
+		{
+			name: "built-in fairground",
+			modify: func(config *NetworkConfig) {
+				*config = FairgroundConfig()
+			},
+		},
+		{
+			name: "seed without node id",
+			modify: func(config *NetworkConfig) {
+				config.TendermintSeeds = []string{"seed0.example.com:26656"}
+			},
+			wantErr: "tendermint-seeds[0]: invalid tendermint peer",
+		},
+		{
+			name: "seed with short node id",
+			modify: func(config *NetworkConfig) {
+				config.TendermintSeeds = []string{"b0db58f5@seed0.example.com:26656"}
+			},
+			wantErr: "tendermint-seeds[0]: invalid tendermint peer",
+		},
+		{
+			name: "seed without port",
+			modify: func(config *NetworkConfig) {
+				config.TendermintSeeds = []string{testNodeID + "@seed0.example.com"}
+			},
+			wantErr: "tendermint-seeds[0]: invalid address",
+		},
+		{
+			name: "persistent peer with invalid port",
+			modify: func(config *NetworkConfig) {
+				config.TendermintPersistentPeers = []string{testNodeID + "@peer0.example.com:65536"}
+			},
+			wantErr: "tendermint-persistent-peers[0]: invalid port '65536'",
+		},
+		{
+			name: "ip4 multiaddr",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/ip4/10.0.0.1/tcp/4001/p2p/" + testPeerID
+			},
+		},
+		{
+			name: "ip6 multiaddr",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/ip6/::1/tcp/4001/ipfs/" + testPeerID
+			},
+		},
+		{
+			name: "multiaddr with invalid ip4",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/ip4/10.0.0.256/tcp/4001/ipfs/" + testPeerID
+			},
+			wantErr: "bootstrap-peers[0].endpoint: invalid multiaddr '/ip4/10.0.0.256/tcp/4001/ipfs/" + testPeerID + "': invalid ipv4 address",
+		},
+		{
+			name: "multiaddr with ip4 in ip6",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/ip6/10.0.0.1/tcp/4001/ipfs/" + testPeerID
+			},
+			wantErr: "invalid ipv6 address",
+		},
+		{
+			name: "multiaddr with invalid port",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/dns/api0.example.com/tcp/0/ipfs/" + testPeerID
+			},
+			wantErr: "invalid port '0'",
+		},
+		{
+			name: "multiaddr with invalid peer id",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "/dns/api0.example.com/tcp/4001/ipfs/12D3KooWinvalid"
+			},
+			wantErr: "invalid peer id '12D3KooWinvalid'",
+		},
+		{
+			name: "multiaddr without protocol",
+			modify: func(config *NetworkConfig) {
+				config.BootstrapPeers[0].Endpoint = "api0.example.com:4001"
+			},
+			wantErr: "bootstrap-peers[0].endpoint: invalid multiaddr 'api0.example.com:4001'",
+		},
+		{
+			name: "invalid genesis version",
+			modify: func(config *NetworkConfig) {
+				config.GenesisVersion = "0.73.4"
+			},
+			wantErr: "genesis-version: invalid semver version '0.73.4'",
+		},
+		{
+			name: "invalid lowest visor version",
+			modify: func(config *NetworkConfig) {
+				config.LowestVisorVersion = "latest"
+			},
+			wantErr: "lowest-visor-version: invalid semver version 'latest'",
+		},
+		{
+			name: "pre-release genesis version",
+			modify: func(config *NetworkConfig) {
+				config.GenesisVersion = "v0.74.0-preview.1"
+			},
+		},
+		{
+			name: "invalid binaries override",
+			modify: func(config *NetworkConfig) {
+				config.BinariesOverride = []BinaryOverride{{OldVersion: "v0.73.4", NewVersion: "v0.73.5-fix", Block: 0}}
+			},
+			wantErr: "binaries-override[0].block: must be greater than 0",
+		},
+		{
+			name: "missing data-node urls",
+			modify: func(config *NetworkConfig) {
+				config.DataNodesRESTUrls = nil
+			},
+			wantErr: "data-nodes-rest-urls: at least one url required",
+		},
+		{
+			name: "invalid repository",
+			modify: func(config *NetworkConfig) {
+				config.Repository = "https://github.com/vegaprotocol/vega"
+			},
+			wantErr: "repository: expected <owner>/<repository>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validTestConfig()
+			tt.modify(&config)
+
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected valid config, got: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got: %s", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	config := validTestConfig()
+	config.Name = ""
+	config.GenesisURL = "ftp://example.com/genesis.json"
+	config.TendermintRPCServers = nil
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	for _, expected := range []string{
+		"name: must not be empty",
+		"genesis-url: invalid url 'ftp://example.com/genesis.json': expected http or https scheme",
+		"tendermint-rpc-servers: at least one server required",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got: %s", expected, err)
+		}
+	}
+}
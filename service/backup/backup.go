@@ -0,0 +1,268 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// Action describes what happens with the existing home before the node is set up
+type Action string
+
+const (
+	// ActionArchive writes the home into the tar.gz archive in the backups directory and removes it
+	ActionArchive Action = "archive"
+	// ActionRename keeps the home next to the original path with the `.backup-<timestamp>` suffix
+	ActionRename Action = "rename"
+	// ActionRemove removes the home without a backup
+	ActionRemove Action = "remove"
+)
+
+const (
+	archiveExtension = ".tar.gz"
+	timestampFormat  = "20060102150405"
+)
+
+// chainDataPaths are directories with the chain data, relative to the vega and tendermint homes. They are large
+// and the node restores them from the network, so they can be excluded from the archive.
+var chainDataPaths = []string{
+	// tendermint home
+	"data/blockstore.db",
+	"data/state.db",
+	"data/tx_index.db",
+	"data/evidence.db",
+	"data/cs.wal",
+	// vega home
+	"state/node/snapshots",
+	"state/data-node/networkhistory",
+}
+
+var renamedBackupRegex = regexp.MustCompile(`\.backup-\d{14}$`)
+
+type Settings struct {
+	Action           Action `toml:"action"`
+	Dir              string `toml:"dir"`
+	ExcludeChainData bool   `toml:"exclude-chain-data"`
+}
+
+// Backup describes the archive in the backups directory
+type Backup struct {
+	Path      string
+	Home      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Action: ActionArchive,
+		Dir:    DefaultDir(),
+	}
+}
+
+// DefaultDir returns the default directory for archives
+func DefaultDir() string {
+	return filepath.Join(utils.CurrentUserHomePath(), "vega_backups")
+}
+
+// Validate checks the settings loaded from the config file
+func (settings Settings) Validate() error {
+	switch settings.Action {
+	case ActionArchive, ActionRename, ActionRemove:
+	default:
+		return fmt.Errorf("invalid backup action %q: expected %s, %s or %s", settings.Action, ActionArchive, ActionRename, ActionRemove)
+	}
+
+	if settings.Action == ActionArchive && settings.Dir == "" {
+		return fmt.Errorf("backup dir cannot be empty for the %s action", ActionArchive)
+	}
+
+	return nil
+}
+
+// MoveAsidePath returns the path the home is renamed to before it is backed up
+func MoveAsidePath(homePath string, now time.Time) string {
+	return fmt.Sprintf("%s.backup-%s", homePath, now.Format(timestampFormat))
+}
+
+// HandleMoved finishes the backup of the home already moved aside to the movedPath
+func HandleMoved(logger *zap.SugaredLogger, homePath, movedPath string, action Action, settings Settings) error {
+	switch action {
+	case ActionRename:
+		logger.Infof("Previous %s kept in %s. Restore it with the `vega-assistant backup restore %s` command", homePath, movedPath, movedPath)
+		return nil
+
+	case ActionArchive:
+		archivePath, err := archive(logger, homePath, movedPath, settings)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", homePath, err)
+		}
+		logger.Infof("Previous %s archived in %s. Restore it with the `vega-assistant backup restore %s` command", homePath, archivePath, archivePath)
+	}
+
+	logger.Infof("Removing %s", movedPath)
+	if err := os.RemoveAll(movedPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", movedPath, err)
+	}
+
+	return nil
+}
+
+func archive(logger *zap.SugaredLogger, homePath, srcDir string, settings Settings) (string, error) {
+	if err := os.MkdirAll(settings.Dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create backups directory %s: %w", settings.Dir, err)
+	}
+
+	archivePath := filepath.Join(
+		settings.Dir,
+		fmt.Sprintf("%s-%s%s", filepath.Base(homePath), time.Now().Format(timestampFormat), archiveExtension),
+	)
+
+	var skip func(string) bool
+	if settings.ExcludeChainData {
+		skip = isChainData
+	}
+
+	logger.Infof("Archiving %s into %s", homePath, archivePath)
+	if err := utils.TarGzDir(srcDir, archivePath, homePath, skip); err != nil {
+		os.Remove(archivePath)
+		return "", err
+	}
+
+	return archivePath, nil
+}
+
+func isChainData(relPath string) bool {
+	for _, chainDataPath := range chainDataPaths {
+		if filepath.ToSlash(relPath) == chainDataPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Restore puts the backup (archive or renamed home) back in place. When the destination is empty,
+// the original home path is used. The existing destination is replaced only with the force flag.
+func Restore(logger *zap.SugaredLogger, backupPath, destination string, force bool) (string, error) {
+	if !utils.FileExists(backupPath) {
+		return "", fmt.Errorf("backup %s does not exist", backupPath)
+	}
+
+	isArchive := strings.HasSuffix(backupPath, archiveExtension)
+	if destination == "" {
+		if isArchive {
+			home, err := utils.TarGzName(backupPath)
+			if err != nil {
+				return "", err
+			}
+			destination = home
+		} else {
+			destination = renamedBackupRegex.ReplaceAllString(backupPath, "")
+		}
+	}
+
+	if destination == "" || destination == backupPath {
+		return "", fmt.Errorf("cannot find the original home for %s: provide the destination", backupPath)
+	}
+
+	if utils.FileExists(destination) && !force {
+		return "", fmt.Errorf("destination %s exists: remove it or use the force flag", destination)
+	}
+
+	if !isArchive {
+		if err := removeExisting(logger, destination); err != nil {
+			return "", err
+		}
+
+		logger.Infof("Moving %s to %s", backupPath, destination)
+		if err := os.Rename(backupPath, destination); err != nil {
+			return "", fmt.Errorf("failed to restore %s: %w", backupPath, err)
+		}
+
+		return destination, nil
+	}
+
+	// Extract next to the destination first, so the destination does not contain partially extracted files
+	extractPath := destination + ".restoring"
+	logger.Infof("Extracting %s into %s", backupPath, destination)
+	// Absolute symlinks in the home point to the destination, e.g. the `current` version in the visor home
+	if err := utils.UntarGz(backupPath, extractPath, destination); err != nil {
+		os.RemoveAll(extractPath)
+		return "", fmt.Errorf("failed to extract %s: %w", backupPath, err)
+	}
+	// The existing destination is removed only when the backup is extracted successfully
+	if err := removeExisting(logger, destination); err != nil {
+		os.RemoveAll(extractPath)
+		return "", err
+	}
+	if err := os.Rename(extractPath, destination); err != nil {
+		os.RemoveAll(extractPath)
+		return "", fmt.Errorf("failed to move restored files to %s: %w", destination, err)
+	}
+
+	return destination, nil
+}
+
+func removeExisting(logger *zap.SugaredLogger, destination string) error {
+	if !utils.FileExists(destination) {
+		return nil
+	}
+
+	logger.Infof("Removing existing %s", destination)
+	if err := os.RemoveAll(destination); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", destination, err)
+	}
+
+	return nil
+}
+
+// List returns archives from the backups directory sorted from the newest
+func List(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Backup{}, nil
+		}
+		return nil, fmt.Errorf("failed to read backups directory %s: %w", dir, err)
+	}
+
+	result := []Backup{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), archiveExtension) {
+			continue
+		}
+
+		archivePath := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s: %w", archivePath, err)
+		}
+
+		home, err := utils.TarGzName(archivePath)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, Backup{
+			Path:      archivePath,
+			Home:      home,
+			Size:      info.Size(),
+			CreatedAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+func TestRestoreArchive(t *testing.T) {
+	tests := []struct {
+		name         string
+		writeArchive func(t *testing.T, archivePath string)
+		wantErr      string
+		wantContent  string
+	}{
+		{
+			name: "valid archive replaces the destination",
+			writeArchive: func(t *testing.T, archivePath string) {
+				srcDir := t.TempDir()
+				if err := os.WriteFile(filepath.Join(srcDir, "config.toml"), []byte("restored"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				if err := utils.TarGzDir(srcDir, archivePath, "", nil); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantContent: "restored",
+		},
+		{
+			name: "archive with parent directory path",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeTarGz(t, archivePath, []*tar.Header{
+					{Name: "config.toml", Typeflag: tar.TypeReg, Mode: 0o600},
+					{Name: "../escaped.txt", Typeflag: tar.TypeReg, Mode: 0o600},
+				})
+			},
+			wantErr:     "destination is outside of the output directory",
+			wantContent: "existing",
+		},
+		{
+			name: "archive with escaping symlink",
+			writeArchive: func(t *testing.T, archivePath string) {
+				writeTarGz(t, archivePath, []*tar.Header{
+					{Name: "config.toml", Typeflag: tar.TypeReg, Mode: 0o600},
+					{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+				})
+			},
+			wantErr:     "pointing outside of the output directory",
+			wantContent: "existing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			archivePath := filepath.Join(workDir, "home-20240101000000"+archiveExtension)
+			destination := filepath.Join(workDir, "home")
+			tt.writeArchive(t, archivePath)
+
+			if err := os.MkdirAll(destination, os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(destination, "config.toml"), []byte("existing"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Restore(zap.NewNop().Sugar(), archivePath, destination, true)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+
+			if _, err := os.Lstat(destination + ".restoring"); !os.IsNotExist(err) {
+				t.Errorf("partially restored %s.restoring must be removed", destination)
+			}
+			if _, err := os.Lstat(filepath.Join(workDir, "escaped.txt")); !os.IsNotExist(err) {
+				t.Errorf("file outside of the destination must not be extracted")
+			}
+
+			content, err := os.ReadFile(filepath.Join(destination, "config.toml"))
+			if err != nil {
+				t.Fatalf("failed to read restored config: %s", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("expected config content %q, got %q", tt.wantContent, string(content))
+			}
+		})
+	}
+}
+
+func writeTarGz(t *testing.T, archivePath string, headers []*tar.Header) {
+	t.Helper()
+
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, header := range headers {
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+const answerAbort = "abort"
+
+// AskAction asks what to do with the existing home. It returns an error when the user aborts the setup.
+func AskAction(ui *input.UI, homePath string, defaultAction Action) (Action, error) {
+	question := fmt.Sprintf(`Home %s exists. What do you want to do with it?
+
+  - %s - write it into the tar.gz archive and remove it
+  - %s - keep it next to the original path with the .backup-<timestamp> suffix
+  - %s - remove it without a backup
+  - %s - stop the setup`, homePath, ActionArchive, ActionRename, ActionRemove, answerAbort)
+
+	answer, err := ui.Ask(question, &input.Options{
+		Default:  string(defaultAction),
+		Required: true,
+		Loop:     true,
+		ValidateFunc: func(s string) error {
+			switch strings.ToLower(s) {
+			case string(ActionArchive), string(ActionRename), string(ActionRemove), answerAbort:
+				return nil
+			}
+			return fmt.Errorf("invalid response; got %s, expected %s, %s, %s or %s", s, ActionArchive, ActionRename, ActionRemove, answerAbort)
+		},
+	})
+	if err != nil {
+		return "", types.NewInputError(err)
+	}
+
+	if strings.ToLower(answer) == answerAbort {
+		return "", fmt.Errorf("%s exists. You must provide different home or back it up", homePath)
+	}
+
+	return Action(strings.ToLower(answer)), nil
+}
@@ -0,0 +1,92 @@
+package datanode
+
+import (
+	"fmt"
+
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/uilib"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// PasswordMode describes how the SQL password is written to the saved config file
+type PasswordMode string
+
+const (
+	SavePasswordOmit  PasswordMode = "omit"
+	SavePasswordEnv   PasswordMode = "env"
+	SavePasswordPlain PasswordMode = "plain"
+)
+
+// DefaultSQLPasswordEnv is the environment variable the saved config refers to in the SavePasswordEnv mode
+const DefaultSQLPasswordEnv = "VEGA_SQL_PASSWORD"
+
+const defaultSaveConfigPath = "config.toml"
+
+// saveConfig writes the answers to the config file. When the path is not given,
+// the user is asked if the answers should be saved. The saved config is non-interactive,
+// so the same setup can be replayed on other hosts with the --config-file flag.
+func (state *StateMachine) saveConfig(ui *input.UI) error {
+	configPath := state.Settings.SaveConfigPath
+	if configPath == "" {
+		if state.Settings.NonInteractive {
+			return nil
+		}
+
+		answer, err := uilib.AskYesNo(ui, "Do you want to save your answers to the config file?", uilib.AnswerNo)
+		if err != nil {
+			return fmt.Errorf("failed asking for saving the config file: %w", err)
+		}
+		if answer == uilib.AnswerNo {
+			return nil
+		}
+
+		configPath, err = uilib.AskPath(ui, "config file", defaultSaveConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed getting config file path: %w", err)
+		}
+	}
+
+	settings := ConfigFileSettings(state.Settings)
+	if err := utils.SaveSettings(configPath, &settings); err != nil {
+		return fmt.Errorf("failed to save answers: %w", err)
+	}
+
+	state.logger.Infof("Answers saved to %s. Use it with the --config-file flag to repeat the setup", configPath)
+	if settings.WithDataNode() {
+		switch state.Settings.SaveConfigPassword {
+		case SavePasswordPlain:
+			state.logger.Infof("The SQL password is saved in plain text. Keep the %s file secret", configPath)
+		case SavePasswordEnv:
+			state.logger.Infof("The SQL password is not saved. Set the %s environment variable before you use the config", settings.SQLCredentials.PassEnv)
+		default:
+			state.logger.Infof("The SQL password is not saved. Add it to the %s file before you use the config", configPath)
+		}
+	}
+
+	return nil
+}
+
+// ConfigFileSettings returns the settings ready to be saved in the config file for the non-interactive setup
+func ConfigFileSettings(settings GenerateSettings) GenerateSettings {
+	settings.NonInteractive = true
+	// The user has agreed to replace the existing homes, the non-interactive setup replaces them only with this flag
+	if len(settings.HomeActions) > 0 {
+		settings.RemoveExistingFiles = true
+	}
+
+	switch settings.SaveConfigPassword {
+	case SavePasswordPlain:
+		settings.SQLCredentials.PassEnv = ""
+	case SavePasswordEnv:
+		settings.SQLCredentials.Pass = ""
+		if settings.SQLCredentials.PassEnv == "" {
+			settings.SQLCredentials.PassEnv = DefaultSQLPasswordEnv
+		}
+	default:
+		settings.SQLCredentials.Pass = ""
+		settings.SQLCredentials.PassEnv = ""
+	}
+
+	return settings
+}
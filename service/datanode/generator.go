@@ -5,15 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strconv"
-	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/daniel1302/vega-assistant/github"
 	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/service/node"
 	"github.com/daniel1302/vega-assistant/types"
 	"github.com/daniel1302/vega-assistant/utils"
 	"github.com/daniel1302/vega-assistant/vegaapi"
@@ -21,200 +21,565 @@ import (
 )
 
 type DataNodeGenerator struct {
-	vegaApi       *vegaapi.NetworkAPI
-	userSettings  GenerateSettings
-	networkConfig network.NetworkConfig
+	vegaApi         *vegaapi.NetworkAPI
+	userSettings    GenerateSettings
+	networkConfig   network.NetworkConfig
+	downloadOptions github.DownloadOptions
+
+	identity         *node.Identity
+	retainedIdentity []string
+}
+
+// Plan describes all the changes the generator makes on the disk
+type Plan struct {
+	RemoveDirs      []string
+	BackupActions   map[string]backup.Action
+	CreateDirs      []string
+	RetainFiles     []string
+	Binaries        []node.BinarySource
+	ConfigFiles     []node.ConfigFile
+	GenesisURL      string
+	GenesisPath     string
+	RestartSnapshot *types.CoreSnapshot
 }
 
 func NewDataNodeGenerator(
 	vegaApi *vegaapi.NetworkAPI,
 	settings GenerateSettings,
 	networkConfig network.NetworkConfig,
+	downloadOptions github.DownloadOptions,
 ) (*DataNodeGenerator, error) {
 	return &DataNodeGenerator{
-		vegaApi:       vegaApi,
-		userSettings:  settings,
-		networkConfig: networkConfig,
+		vegaApi:         vegaApi,
+		userSettings:    settings,
+		networkConfig:   networkConfig,
+		downloadOptions: downloadOptions,
 	}, nil
 }
 
+// DownloadedFiles are the binaries and genesis downloaded for the node
+type DownloadedFiles struct {
+	VegaBinaryPath  string `json:"vega-binary-path"`
+	VisorBinaryPath string `json:"visor-binary-path"`
+	GenesisPath     string `json:"genesis-path"`
+}
+
+// installFiles are the files downloaded and generated before the homes are touched
+type installFiles struct {
+	DownloadedFiles
+	configFiles []node.ConfigFile
+}
+
+// installStep is the single step of the node installation
+type installStep struct {
+	step Step
+	run  func() error
+}
+
+// initPaths are the paths, relative to the home, created by the init of the given step
+var initPaths = map[Step][]string{
+	StepInitVisor:      {"config.toml", "genesis"},
+	StepInitTendermint: {"config", "data"},
+	StepInitVega: {
+		filepath.Join("config", "node"),
+		filepath.Join("data", "node"),
+		filepath.Join("state", "node"),
+		filepath.Join("cache", "node"),
+	},
+	StepInitDataNode: {
+		filepath.Join("config", "data-node"),
+		filepath.Join("data", "data-node"),
+		filepath.Join("state", "data-node"),
+		filepath.Join("cache", "data-node"),
+	},
+}
+
+// Run sets up the node. All the lookups and downloads are done before the homes are touched.
+// When any step fails, the homes are rolled back to the state from before the setup.
 func (gen *DataNodeGenerator) Run(logger *zap.SugaredLogger) error {
+	restartSnapshot, err := gen.selectSnapshotForRestart(context.Background(), logger)
+	if err != nil {
+		return fmt.Errorf("failed to select snapshot for restart: %w", err)
+	}
+
 	outputDir, err := os.MkdirTemp("", "vega-assistant")
 	if err != nil {
 		return fmt.Errorf("failed to create temp dir: %w", err)
 	}
-	//	defer os.RemoveAll(outputDir)
+	defer os.RemoveAll(outputDir)
 
-	logger.Info("Downloading vega binary")
-	vegaBinaryPath, err := github.DownloadArtifact(
-		gen.networkConfig.Repository,
-		gen.userSettings.VegaBinaryVersion,
-		outputDir,
-		github.ArtifactVega,
-	)
+	downloadedFiles, err := gen.downloadFiles(logger, outputDir)
 	if err != nil {
-		return fmt.Errorf("failed to download vega binary: %w", err)
+		return err
 	}
-	logger.Infof("Vega downloaded to %s", vegaBinaryPath)
 
-	logger.Info("Downloading visor binary")
-	visorBinaryPath, err := github.DownloadArtifact(
-		gen.networkConfig.Repository,
-		gen.userSettings.VisorBinaryVersion,
-		outputDir,
-		github.ArtifactVisor,
-	)
+	configFiles, err := gen.configFiles(logger, restartSnapshot)
 	if err != nil {
-		return fmt.Errorf("failed to download visor binary: %w", err)
+		return fmt.Errorf("failed to prepare config files for the node: %w", err)
 	}
-	logger.Infof("Visor downloaded to %s", visorBinaryPath)
 
-	logger.Info("Checking binaries versions")
-	vegaVersion, err := utils.ExecuteBinary(vegaBinaryPath, []string{"version"}, nil)
+	gen.identity, err = gen.readIdentity()
 	if err != nil {
-		return fmt.Errorf("failed to check vega version: %w", err)
+		return err
 	}
-	logger.Infof("Vega version is %s", vegaVersion)
-	VisorBinaryVersion, err := utils.ExecuteBinary(visorBinaryPath, []string{"version"}, nil)
+
+	transaction, err := node.BeginHomesTransaction(logger, gen.homes()...)
 	if err != nil {
-		return fmt.Errorf("failed to check visor version: %w", err)
+		return fmt.Errorf("failed to prepare homes: %w", err)
 	}
-	logger.Infof("Visor version is %s", VisorBinaryVersion)
 
-	if err := gen.initNode(logger, visorBinaryPath, vegaBinaryPath); err != nil {
-		return fmt.Errorf("failed to init vega node: %w", err)
+	files := &installFiles{DownloadedFiles: *downloadedFiles, configFiles: configFiles}
+	for _, step := range gen.installSteps(logger, files) {
+		if err := step.run(); err != nil {
+			logger.Infof("Setup failed: %s", err.Error())
+			if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("%w: rollback failed, clean the homes manually: %s", err, rollbackErr.Error())
+			}
+
+			return err
+		}
+	}
+
+	if err := transaction.Commit(gen.userSettings.HomeActions, gen.userSettings.Backup); err != nil {
+		return fmt.Errorf("node is ready but failed to remove previous homes: %w", err)
 	}
 
-	if err := gen.prepareVisorHome(logger); err != nil {
-		return fmt.Errorf("failed to prepare visor home: %w", err)
+	return nil
+}
+
+// RunWithJournal sets up the node and records completed steps in the journal. The journal is saved next to
+// the visor home before the homes are touched, so the setup can be resumed when any step fails, including
+// the network lookups and downloads. The homes are not rolled back when the setup fails, the next run with
+// the same journal skips completed steps and uses the same snapshot and downloaded files. The existing homes
+// stay moved aside until the setup is finished, so the node identity is read from them again on resume.
+func (gen *DataNodeGenerator) RunWithJournal(logger *zap.SugaredLogger, journal *Journal) error {
+	if err := gen.runJournalSteps(logger, journal, gen.prepareSteps(logger, journal)); err != nil {
+		return err
+	}
+	if journal.Files == nil {
+		return fmt.Errorf("the journal(%s) has no downloaded files, remove it and run the setup again", JournalPath(journal.Settings.VisorHome))
 	}
 
-	if err := gen.copyBinaries(logger, vegaBinaryPath, visorBinaryPath); err != nil {
-		return fmt.Errorf("failed to copy binaries to visor home: %w", err)
+	files := &installFiles{DownloadedFiles: *journal.Files}
+	// Config values are not kept in the journal, they are looked up again until the configs are updated.
+	// On the fresh run it happens before the homes are replaced.
+	if !journal.Completed(StepUpdateConfigs) {
+		configFiles, err := gen.configFiles(logger, journal.RestartSnapshot)
+		if err != nil {
+			return resumeHint(fmt.Errorf("failed to prepare config files for the node: %w", err))
+		}
+		files.configFiles = configFiles
 	}
 
-	restartSnapshot, err := gen.selectSnapshotForRestart(context.Background(), logger)
+	transaction, err := gen.journalHomesTransaction(logger, journal)
 	if err != nil {
-		return fmt.Errorf("failed to select snapshot for restart: %w", err)
+		return err
 	}
 
-	if err := gen.updateConfigs(logger, restartSnapshot); err != nil {
-		return fmt.Errorf("failed to update config files for the node: %w", err)
+	gen.identity, err = gen.readMovedIdentity(journal)
+	if err != nil {
+		return resumeHint(err)
 	}
 
-	if err := gen.downloadGenesis(logger); err != nil {
-		return fmt.Errorf("failed to download genesis: %w", err)
+	if err := gen.runJournalSteps(logger, journal, gen.installSteps(logger, files)); err != nil {
+		return err
 	}
-	return nil
-}
 
-func (gen *DataNodeGenerator) downloadGenesis(logger *zap.SugaredLogger) error {
-	genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
-	logger.Infof("Downloading genesis.json file from %s", gen.networkConfig.GenesisURL)
-	if err := utils.DownloadFile(gen.networkConfig.GenesisURL, genesisDestination); err != nil {
-		return fmt.Errorf("failed to download genesis: %w", err)
+	if err := transaction.Commit(gen.userSettings.HomeActions, gen.userSettings.Backup); err != nil {
+		return fmt.Errorf("node is ready but failed to remove previous homes: %w", err)
 	}
-	logger.Infof("Genesis downloaded to %s", genesisDestination)
 
-	return nil
+	return journal.Remove()
 }
 
-func (gen *DataNodeGenerator) copyBinaries(
-	logger *zap.SugaredLogger,
-	vegaBinaryPath, visorBinaryPath string,
-) error {
-	vegavisorDstFilePath := filepath.Join(gen.userSettings.VisorHome, "visor")
-	logger.Infof("Copying vegavisor from %s to %s", visorBinaryPath, vegavisorDstFilePath)
-	if err := utils.CopyFile(visorBinaryPath, vegavisorDstFilePath); err != nil {
-		return fmt.Errorf("failed to copy visor binary: %w", err)
+// journalHomesTransaction moves the existing homes aside and records their new paths in the journal. When the
+// homes have been already moved by the previous run, the transaction is resumed from the journal.
+func (gen *DataNodeGenerator) journalHomesTransaction(logger *zap.SugaredLogger, journal *Journal) (*node.HomesTransaction, error) {
+	if journal.Completed(StepReplaceHomes) {
+		logger.Infof("The %s step has been already completed. Skipping it", StepReplaceHomes)
+		return node.ResumeHomesTransaction(logger, journal.MovedHomes, gen.homes()...), nil
 	}
-	logger.Info("Visor binary copied")
 
-	version := gen.userSettings.VegaBinaryVersion
-	if gen.userSettings.Mode == StartFromBlock0 {
-		version = "genesis"
+	transaction, err := node.BeginHomesTransaction(logger, gen.homes()...)
+	if err != nil {
+		// The homes are restored, nothing is left to resume
+		if removeErr := journal.Remove(); removeErr != nil {
+			logger.Errorf("Failed to remove setup journal: %s", removeErr.Error())
+		}
+		return nil, fmt.Errorf("failed to prepare homes: %w", err)
 	}
 
-	vegaDstFilePath := filepath.Join(gen.userSettings.VisorHome, version, "vega")
-	logger.Infof("Copying vega from %s to %s", vegaBinaryPath, vegaDstFilePath)
-	if err := utils.CopyFile(vegaBinaryPath, vegaDstFilePath); err != nil {
-		return fmt.Errorf("failed to copy vega binary: %w", err)
+	journal.MovedHomes = transaction.Backups()
+	if err := journal.Complete(StepReplaceHomes); err != nil {
+		return nil, fmt.Errorf("failed to record the %s step: %w", StepReplaceHomes, err)
 	}
-	logger.Info("Vega binary copied")
 
-	versionDirectory := filepath.Join(gen.userSettings.VisorHome, version)
-	currentDirectory := filepath.Join(gen.userSettings.VisorHome, "current")
-	logger.Infof("Creating symlink from %s to %s", versionDirectory, currentDirectory)
-	if err := os.Symlink(versionDirectory, currentDirectory); err != nil {
-		return fmt.Errorf(
-			"failed to create symlink from %s to %s: %w",
-			versionDirectory,
-			currentDirectory,
-			err,
-		)
+	return transaction, nil
+}
+
+// readMovedIdentity reads the node identity from the homes moved aside by the setup
+func (gen *DataNodeGenerator) readMovedIdentity(journal *Journal) (*node.Identity, error) {
+	if !gen.userSettings.PreserveIdentity {
+		return nil, nil
+	}
+
+	identity := &node.Identity{}
+	for kind, home := range map[node.IdentityHome]string{
+		node.IdentityHomeVega:       gen.userSettings.VegaHome,
+		node.IdentityHomeTendermint: gen.userSettings.TendermintHome,
+	} {
+		movedPath, ok := journal.MovedHomes[home]
+		if !ok {
+			continue
+		}
+		if err := identity.Read(kind, movedPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return identity, nil
+}
+
+// runJournalSteps runs steps which are not completed yet and records them in the journal
+func (gen *DataNodeGenerator) runJournalSteps(logger *zap.SugaredLogger, journal *Journal, steps []installStep) error {
+	for _, step := range steps {
+		if journal.Completed(step.step) {
+			logger.Infof("The %s step has been already completed. Skipping it", step.step)
+			continue
+		}
+
+		if err := step.run(); err != nil {
+			return resumeHint(err)
+		}
+
+		if err := journal.Complete(step.step); err != nil {
+			return fmt.Errorf("failed to record the %s step: %w", step.step, err)
+		}
 	}
-	logger.Info("Symlink created")
 
 	return nil
 }
 
-func (gen *DataNodeGenerator) prepareVisorHome(logger *zap.SugaredLogger) error {
-	runConfigDirPath := filepath.Join(gen.userSettings.VisorHome, gen.userSettings.VegaBinaryVersion)
-	version := gen.userSettings.VegaBinaryVersion
+// prepareSteps returns steps which select the snapshot and download files before the homes are touched
+func (gen *DataNodeGenerator) prepareSteps(logger *zap.SugaredLogger, journal *Journal) []installStep {
+	return []installStep{
+		{
+			step: StepSelectSnapshot,
+			run: func() error {
+				restartSnapshot, err := gen.selectSnapshotForRestart(context.Background(), logger)
+				if err != nil {
+					return fmt.Errorf("failed to select snapshot for restart: %w", err)
+				}
+				journal.RestartSnapshot = restartSnapshot
+				return nil
+			},
+		},
+		{
+			step: StepDownloadFiles,
+			run: func() error {
+				// The files are kept next to the journal, so the interrupted download is resumed by the next run
+				filesDir := journal.FilesDir()
+				if err := os.MkdirAll(filesDir, os.ModePerm); err != nil {
+					return fmt.Errorf("failed to create directory for downloaded files: %w", err)
+				}
+				downloadedFiles, err := gen.downloadFiles(logger, filesDir)
+				if err != nil {
+					return err
+				}
+				journal.Files = downloadedFiles
+				return nil
+			},
+		},
+	}
+}
 
-	if gen.userSettings.Mode == StartFromBlock0 {
-		runConfigDirPath = filepath.Join(gen.userSettings.VisorHome, "genesis")
-		version = "genesis"
+// resumeHint tells the user how to continue the failed setup recorded in the journal
+func resumeHint(err error) error {
+	return fmt.Errorf("%w: fix the problem and run the setup with the --resume flag to continue", err)
+}
+
+// downloadFiles downloads binaries and genesis into the output directory
+func (gen *DataNodeGenerator) downloadFiles(logger *zap.SugaredLogger, outputDir string) (*DownloadedFiles, error) {
+	vegaBinaryPath, visorBinaryPath, err := node.DownloadBinaries(
+		logger,
+		gen.networkConfig,
+		gen.downloadOptions,
+		gen.userSettings.VegaBinaryVersion,
+		gen.userSettings.VisorBinaryVersion,
+		outputDir,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download binaries: %w", err)
 	}
 
-	logger.Infof("Preparing %s folder for vega", runConfigDirPath)
-	if err := os.MkdirAll(runConfigDirPath, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to make directory: %w", err)
+	genesisPath := filepath.Join(outputDir, "genesis.json")
+	if err := utils.DownloadFile(gen.networkConfig.GenesisURL, genesisPath); err != nil {
+		return nil, fmt.Errorf("failed to download genesis: %w", err)
 	}
-	logger.Infof("Folder %s created", runConfigDirPath)
 
-	runConfigPath := filepath.Join(runConfigDirPath, "run-config.toml")
-	logger.Infof("Preparing run-config toml file in %s", runConfigPath)
-	runConfigContent, err := vegacmd.TemplateVisorRunConfig(
-		version,
+	return &DownloadedFiles{
+		VegaBinaryPath:  vegaBinaryPath,
+		VisorBinaryPath: visorBinaryPath,
+		GenesisPath:     genesisPath,
+	}, nil
+}
+
+// installSteps returns steps which initialize the node in the homes and put binaries, configs and genesis in place
+func (gen *DataNodeGenerator) installSteps(logger *zap.SugaredLogger, files *installFiles) []installStep {
+	steps := []installStep{
+		gen.initStep(logger, StepInitVisor, "vegavisor", gen.userSettings.VisorHome, func() error {
+			return vegacmd.InitVisor(files.VisorBinaryPath, gen.userSettings.VisorHome)
+		}),
+		gen.initStep(logger, StepInitTendermint, "tendermint", gen.userSettings.TendermintHome, func() error {
+			return vegacmd.InitTendermint(files.VegaBinaryPath, gen.userSettings.TendermintHome)
+		}),
+		gen.initStep(logger, StepInitVega, "vega", gen.userSettings.VegaHome, func() error {
+			return vegacmd.InitVega(files.VegaBinaryPath, gen.userSettings.VegaHome, vegacmd.VegaNodeFull)
+		}),
+	}
+	if gen.userSettings.WithDataNode() {
+		steps = append(steps, gen.initStep(logger, StepInitDataNode, "data-node", gen.userSettings.DataNodeHome, func() error {
+			return vegacmd.InitDataNode(files.VegaBinaryPath, gen.userSettings.DataNodeHome, gen.userSettings.VegaChainId)
+		}))
+	}
+
+	return append(steps, []installStep{
+		{
+			step: StepRestoreIdentity,
+			run: func() error {
+				retained, err := gen.identity.Restore(logger, gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+				if err != nil {
+					return fmt.Errorf("failed to restore node identity: %w", err)
+				}
+				gen.retainedIdentity = retained
+				return nil
+			},
+		},
+		{
+			step: StepPrepareVisorHome,
+			run: func() error {
+				if err := node.PrepareVisorHome(logger, gen.userSettings.VisorHome, gen.visorRunConfig()); err != nil {
+					return fmt.Errorf("failed to prepare visor home: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			step: StepCopyBinaries,
+			run: func() error {
+				if err := node.CopyBinaries(logger, gen.userSettings.VisorHome, gen.visorVersionName(), files.VegaBinaryPath, files.VisorBinaryPath); err != nil {
+					return fmt.Errorf("failed to copy binaries to visor home: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			step: StepUpdateConfigs,
+			run: func() error {
+				for _, configFile := range files.configFiles {
+					if err := node.UpdateConfigFile(logger, configFile.Name, configFile.Path, configFile.Values); err != nil {
+						return fmt.Errorf("failed to update config files for the node: %w", err)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			step: StepCopyGenesis,
+			run: func() error {
+				genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
+				logger.Infof("Copying genesis from %s to %s", files.GenesisPath, genesisDestination)
+				if err := utils.CopyFile(files.GenesisPath, genesisDestination); err != nil {
+					return fmt.Errorf("failed to copy genesis: %w", err)
+				}
+				logger.Info("Genesis copied")
+				return nil
+			},
+		},
+	}...)
+}
+
+// Plan does all the lookups the Run does and returns the changes it would make, without touching the disk
+func (gen *DataNodeGenerator) Plan(logger *zap.SugaredLogger) (*Plan, error) {
+	binaries, err := node.LocateBinaries(
+		gen.networkConfig,
+		gen.downloadOptions,
+		gen.userSettings.VegaBinaryVersion,
+		gen.userSettings.VisorBinaryVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate binaries: %w", err)
+	}
+
+	restartSnapshot, err := gen.selectSnapshotForRestart(context.Background(), logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select snapshot for restart: %w", err)
+	}
+
+	configFiles, err := gen.configFiles(logger, restartSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare config files for the node: %w", err)
+	}
+
+	createDirs := []string{
+		gen.userSettings.VisorHome,
+		filepath.Join(gen.userSettings.VisorHome, gen.visorVersionName()),
 		gen.userSettings.VegaHome,
 		gen.userSettings.TendermintHome,
-	)
+	}
+	if gen.userSettings.WithDataNode() && gen.userSettings.DataNodeHome != gen.userSettings.VegaHome {
+		createDirs = append(createDirs, gen.userSettings.DataNodeHome)
+	}
+
+	identity, err := gen.readIdentity()
 	if err != nil {
-		return fmt.Errorf("failed to generate run-config.toml from template: %w", err)
+		return nil, err
 	}
-	if err := os.WriteFile(runConfigPath, []byte(runConfigContent), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to write run-config.toml in %s: %w", runConfigContent, err)
+
+	removeDirs := node.ExistingHomes(gen.homes()...)
+	backupActions := map[string]backup.Action{}
+	for _, home := range removeDirs {
+		backupActions[home] = gen.userSettings.HomeActions.Action(home, gen.userSettings.Backup.Action)
 	}
-	logger.Infof("The run-config.toml file saved in %s", runConfigPath)
 
-	return nil
+	return &Plan{
+		RemoveDirs:      removeDirs,
+		BackupActions:   backupActions,
+		CreateDirs:      createDirs,
+		RetainFiles:     identity.Paths(gen.userSettings.VegaHome, gen.userSettings.TendermintHome),
+		Binaries:        binaries,
+		ConfigFiles:     configFiles,
+		GenesisURL:      gen.networkConfig.GenesisURL,
+		GenesisPath:     filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath),
+		RestartSnapshot: restartSnapshot,
+	}, nil
+}
+
+// RetainedIdentity returns the paths of the identity files retained from the previous homes
+func (gen *DataNodeGenerator) RetainedIdentity() []string {
+	return gen.retainedIdentity
+}
+
+// readIdentity reads the node identity from the existing homes when the user wants to preserve it
+func (gen *DataNodeGenerator) readIdentity() (*node.Identity, error) {
+	if !gen.userSettings.PreserveIdentity {
+		return nil, nil
+	}
+
+	return node.ReadIdentity(gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+}
+
+// homes returns all the homes of the node
+func (gen *DataNodeGenerator) homes() []string {
+	return []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.DataNodeHome,
+		gen.userSettings.TendermintHome,
+	}
+}
+
+func (gen *DataNodeGenerator) visorRunConfig() vegacmd.VisorRunConfig {
+	return vegacmd.VisorRunConfig{
+		Version:        gen.visorVersionName(),
+		VegaHome:       gen.userSettings.VegaHome,
+		TendermintHome: gen.userSettings.TendermintHome,
+		WithDataNode:   gen.userSettings.WithDataNode(),
+	}
+}
+
+// visorVersionName returns the name of the visor directory for the vega binary
+func (gen *DataNodeGenerator) visorVersionName() string {
+	if gen.userSettings.Mode == StartFromBlock0 {
+		return node.GenesisVersionName
+	}
+
+	return gen.userSettings.VegaBinaryVersion
 }
 
-func (gen *DataNodeGenerator) updateConfigs(
+// configFiles returns new values for the data-node, core, tendermint and vegavisor config files
+func (gen *DataNodeGenerator) configFiles(
 	logger *zap.SugaredLogger,
 	restartSnapshot *types.CoreSnapshot,
-) error {
-	healthyTendermintRPCServers, err := gen.vegaApi.HealthyEndpoints(context.Background(), gen.networkConfig.TendermintRPCServers)
+) ([]node.ConfigFile, error) {
+	healthyTendermintRPCServers, err := node.HealthyRPCServers(context.Background(), gen.vegaApi, gen.networkConfig)
 	if err != nil {
-		return fmt.Errorf("failed to find healthy tendermint rpc servers: %w", err)
+		return nil, fmt.Errorf("failed to get tendermint rpc servers: %w", err)
 	}
 
-	if len(healthyTendermintRPCServers) < 1 {
-		return fmt.Errorf("there is no healthy rpc server")
+	tendermintSeeds, err := node.TendermintSeeds(context.Background(), logger, gen.vegaApi, gen.networkConfig, healthyTendermintRPCServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tendermint seeds: %w", err)
 	}
 
-	if len(healthyTendermintRPCServers) == 1 {
-		healthyTendermintRPCServers = append(healthyTendermintRPCServers, healthyTendermintRPCServers[0])
+	vegaConfig := map[string]interface{}{
+		"Snapshot.StartHeight":      -1,
+		"Broker.Socket.Enabled":     true,
+		"Broker.Socket.DialTimeout": "4h",
+	}
+	if !gen.userSettings.WithDataNode() {
+		vegaConfig["Broker.Socket.Enabled"] = false
 	}
 
+	tendermintConfig := node.TendermintConfig(
+		tendermintSeeds,
+		gen.networkConfig.TendermintPersistentPeers,
+		healthyTendermintRPCServers,
+	)
+
+	vegavisorConfig := node.VisorConfig(gen.networkConfig.Repository)
+
+	if gen.userSettings.Mode == StartFromNetworkHistory {
+		if err := node.EnableStateSync(tendermintConfig, restartSnapshot); err != nil {
+			return nil, fmt.Errorf("failed to start node from network history: %w", err)
+		}
+
+		// We cannot use statis StartHeight value because it is not working when we are syncing more blocks from the data-node
+		// Tendermint does not offer more than 10 snapshots.
+		// vegaConfig["Snapshot.StartHeight"] = trustHeight
+	}
+
+	configFiles := []node.ConfigFile{}
+	if gen.userSettings.WithDataNode() {
+		dataNodeConfig, err := gen.dataNodeConfig(logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare data-node config: %w", err)
+		}
+
+		configFiles = append(configFiles, node.ConfigFile{
+			Name:   "data-node",
+			Path:   filepath.Join(gen.userSettings.DataNodeHome, vegacmd.DataNodeConfigPath),
+			Values: dataNodeConfig,
+		})
+	}
+
+	return append(configFiles,
+		node.ConfigFile{
+			Name:   "vega-core",
+			Path:   filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath),
+			Values: vegaConfig,
+		},
+		node.ConfigFile{
+			Name:   "tendermint",
+			Path:   filepath.Join(gen.userSettings.TendermintHome, vegacmd.TenderminConfigPath),
+			Values: tendermintConfig,
+		},
+		node.ConfigFile{
+			Name:   "vegavisor",
+			Path:   filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath),
+			Values: vegavisorConfig,
+		},
+	), nil
+}
+
+func (gen *DataNodeGenerator) dataNodeConfig(logger *zap.SugaredLogger) (map[string]interface{}, error) {
 	healthyBootstrapPeers, err := gen.vegaApi.HealthyEndpoints(context.Background(), gen.networkConfig.BootstrapPeers)
 	if err != nil {
-		return fmt.Errorf("failed to find healthy network history bootstrap peers: %w", err)
+		return nil, fmt.Errorf("failed to find healthy network history bootstrap peers: %w", err)
 	}
 
-	if len(healthyBootstrapPeers) < 1 {
-		return fmt.Errorf("no healthy network history bootstrap peer")
+	if len(gen.networkConfig.BootstrapPeers) > 0 && len(healthyBootstrapPeers) < 1 {
+		return nil, fmt.Errorf("no healthy network history bootstrap peer")
 	}
 
 	if len(healthyBootstrapPeers) == 1 {
@@ -229,7 +594,6 @@ func (gen *DataNodeGenerator) updateConfigs(
 		"SQLStore.ConnectionConfig.Password":          gen.userSettings.SQLCredentials.Pass,
 		"SQLStore.ConnectionConfig.Database":          gen.userSettings.SQLCredentials.DatabaseName,
 		"SQLStore.WipeOnStartup":                      true,
-		"NetworkHistory.Store.BootstrapPeers":         healthyBootstrapPeers,
 		"NetworkHistory.Initialise.MinimumBlockCount": gen.userSettings.NetworkHistoryMinBlockCount,
 		"NetworkHistory.Initialise.Timeout":           "4h",
 		"NetworkHistory.RetryTimeout":                 "15s",
@@ -239,105 +603,17 @@ func (gen *DataNodeGenerator) updateConfigs(
 		"NetworkHistory.Publish": false,
 	}
 
-	vegaConfig := map[string]interface{}{
-		"Snapshot.StartHeight":      -1,
-		"Broker.Socket.Enabled":     true,
-		"Broker.Socket.DialTimeout": "4h",
-	}
-
-	tendermintConfig := map[string]interface{}{
-		"p2p.seeds":              strings.Join(gen.networkConfig.TendermintSeeds, ","),
-		"p2p.persistent_peers":   strings.Join(gen.networkConfig.TendermintPersistentPeers, ","),
-		"p2p.pex":                true,
-		"statesync.enable":       false,
-		"statesync.rpc_servers":  strings.Join(healthyTendermintRPCServers, ","),
-		"statesync.trust_period": "672h0m0s",
-	}
-
-	vegavisorConfig := map[string]interface{}{
-		"maxNumberOfFirstConnectionRetries": 43200,
-		"autoInstall.enabled":               true,
-		"autoInstall.repositoryOwner":       strings.Split(gen.networkConfig.Repository, "/")[0],
-		"autoInstall.repository":            strings.Split(gen.networkConfig.Repository, "/")[1],
-		"autoInstall.asset.name": fmt.Sprintf(
-			"vega-%s-%s.zip",
-			runtime.GOOS,
-			runtime.GOARCH,
-		),
-		"autoInstall.asset.binaryName": "vega",
+	if len(healthyBootstrapPeers) > 0 {
+		dataNodeConfig["NetworkHistory.Store.BootstrapPeers"] = healthyBootstrapPeers
+	} else {
+		logger.Infof("No network history bootstrap peers defined for the %s network. Using data-node defaults", gen.networkConfig.Name)
 	}
 
 	if gen.userSettings.Mode == StartFromNetworkHistory {
-		if restartSnapshot == nil {
-			return fmt.Errorf(
-				"failed to start node from network history: no selected snapshot for restart",
-			)
-		}
-
-		if restartSnapshot.BlockHash == "" {
-			return fmt.Errorf(
-				"cannot start vega from the network-history when latest snapshot is empty",
-			)
-		}
-
-		trustHeight, err := strconv.Atoi(restartSnapshot.BlockHeight)
-		if err != nil {
-			return fmt.Errorf("failed to convert trust block height from string to int: %w", err)
-		}
-
-		// We cannot use statis StartHeight value because it is not working when we are syncing more blocks from the data-node
-		// Tendermint does not offer more than 10 snapshots.
-		// vegaConfig["Snapshot.StartHeight"] = trustHeight
 		dataNodeConfig["AutoInitialiseFromNetworkHistory"] = true
-		tendermintConfig["statesync.enable"] = true
-		tendermintConfig["statesync.trust_height"] = trustHeight
-		tendermintConfig["statesync.trust_hash"] = restartSnapshot.BlockHash
 	}
 
-	dataNodeConfigPath := filepath.Join(gen.userSettings.DataNodeHome, vegacmd.DataNodeConfigPath)
-	logger.Infof(
-		"Updating data-node config(%s). New parameters: %v",
-		dataNodeConfigPath,
-		dataNodeConfig,
-	)
-	if err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig); err != nil {
-		return fmt.Errorf("failed to update the data-node config; %w", err)
-	}
-	logger.Info("Data-node config updated")
-
-	vegaConfigPath := filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath)
-	logger.Infof("Updating vega-core config(%s). New parameters: %v", vegaConfigPath, vegaConfig)
-	if err := utils.UpdateConfig(vegaConfigPath, "toml", vegaConfig); err != nil {
-		return fmt.Errorf("failed to update the vega config; %w", err)
-	}
-	logger.Info("Vega-core config updated")
-
-	tendermintConfigPath := filepath.Join(
-		gen.userSettings.TendermintHome,
-		vegacmd.TenderminConfigPath,
-	)
-	logger.Infof(
-		"Updating tendermint config(%s). New parameters: %v",
-		tendermintConfigPath,
-		tendermintConfig,
-	)
-	if err := utils.UpdateConfig(tendermintConfigPath, "toml", tendermintConfig); err != nil {
-		return fmt.Errorf("failed to update the tendermint config; %w", err)
-	}
-	logger.Info("Tendermint config updated")
-
-	vegavisorConfigPath := filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath)
-	logger.Infof(
-		"Updating vegavisor config(%s). New parameters: %v",
-		vegavisorConfigPath,
-		vegavisorConfig,
-	)
-	if err := utils.UpdateConfig(vegavisorConfigPath, "toml", vegavisorConfig); err != nil {
-		return fmt.Errorf("failed to update vegavisor config: %w", err)
-	}
-	logger.Info("Vegavisor config updated")
-
-	return nil
+	return dataNodeConfig, nil
 }
 
 func (gen *DataNodeGenerator) selectSnapshotForRestart(
@@ -348,6 +624,11 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 		return &types.CoreSnapshot{}, nil
 	}
 
+	// Without data-node we do not need network history segments, core restores state from snapshot only
+	if !gen.userSettings.WithDataNode() {
+		return node.SelectStateSyncSnapshot(ctx, logger, gen.vegaApi)
+	}
+
 	stats, err := gen.vegaApi.Statistics(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get statistics: %w", err)
@@ -456,49 +737,30 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 	return selectedSnapshot, nil
 }
 
-func (gen *DataNodeGenerator) initNode(
+// initStep returns the step which initializes the single home. Paths created by the init are removed first,
+// so the init interrupted in the previous run can be repeated.
+func (gen *DataNodeGenerator) initStep(
 	logger *zap.SugaredLogger,
-	visorBinary, vegaBinary string,
-) error {
-	logger.Infof("Initializing vegavisor in the %s", gen.userSettings.VisorHome)
-	if err := vegacmd.InitVisor(visorBinary, gen.userSettings.VisorHome); err != nil {
-		return fmt.Errorf(
-			"failed to initialize vegavisor in %s: %w",
-			gen.userSettings.VisorHome,
-			err,
-		)
+	step Step,
+	name, home string,
+	init func() error,
+) installStep {
+	return installStep{
+		step: step,
+		run: func() error {
+			for _, path := range initPaths[step] {
+				if err := os.RemoveAll(filepath.Join(home, path)); err != nil {
+					return fmt.Errorf("failed to clean %s before the init: %w", filepath.Join(home, path), err)
+				}
+			}
+
+			logger.Infof("Initializing %s in the %s", name, home)
+			if err := init(); err != nil {
+				return fmt.Errorf("failed to initialize %s in %s: %w", name, home, err)
+			}
+			logger.Infof("The %s successfully initialized", name)
+
+			return nil
+		},
 	}
-	logger.Info("Visor successfully initialized")
-
-	logger.Infof("Initializing tendermint in the %s", gen.userSettings.TendermintHome)
-	if err := vegacmd.InitTendermint(vegaBinary, gen.userSettings.TendermintHome); err != nil {
-		return fmt.Errorf(
-			"failed to initialize tendermint in %s: %w",
-			gen.userSettings.TendermintHome,
-			err,
-		)
-	}
-	logger.Info("Tendermint successfully initialized")
-
-	logger.Infof("Initializing vega in the %s", gen.userSettings.VegaHome)
-	if err := vegacmd.InitVega(vegaBinary, gen.userSettings.VegaHome, vegacmd.VegaNodeFull); err != nil {
-		return fmt.Errorf(
-			"failed to initialize vega in %s: %w",
-			gen.userSettings.VegaHome,
-			err,
-		)
-	}
-	logger.Info("Visor successfully initialized")
-
-	logger.Infof("Initializing data-node n the %s", gen.userSettings.DataNodeHome)
-	if err := vegacmd.InitDataNode(vegaBinary, gen.userSettings.DataNodeHome, gen.userSettings.VegaChainId); err != nil {
-		return fmt.Errorf(
-			"failed to initialize data-node in %s: %w",
-			gen.userSettings.DataNodeHome,
-			err,
-		)
-	}
-	logger.Info("Data-node successfully initialized")
-
-	return nil
 }
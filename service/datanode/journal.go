@@ -0,0 +1,178 @@
+package datanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
+)
+
+// Step is the part of the setup recorded in the journal when it is completed
+type Step string
+
+const (
+	StepSelectSnapshot   Step = "select-snapshot"
+	StepDownloadFiles    Step = "download-files"
+	StepReplaceHomes     Step = "replace-homes"
+	StepInitVisor        Step = "init-visor"
+	StepInitTendermint   Step = "init-tendermint"
+	StepInitVega         Step = "init-vega"
+	StepInitDataNode     Step = "init-data-node"
+	StepRestoreIdentity  Step = "restore-identity"
+	StepPrepareVisorHome Step = "prepare-visor-home"
+	StepCopyBinaries     Step = "copy-binaries"
+	StepUpdateConfigs    Step = "update-configs"
+	StepCopyGenesis      Step = "copy-genesis"
+)
+
+const (
+	journalDirSuffix      = ".vega-assistant"
+	journalFileName       = "state.json"
+	journalFilesDirName   = "files"
+	journalFilePermission = 0o600
+)
+
+// Journal records completed setup steps and values resolved from the network, so the failed setup
+// can be resumed with the same versions, snapshot and downloaded files. It is kept next to the visor home,
+// because the homes are replaced during the setup. Secrets are not stored in the journal: the SQL password
+// is given again on resume and the node identity is read again from the homes moved aside by the setup.
+type Journal struct {
+	Settings        GenerateSettings    `json:"settings"`
+	RestartSnapshot *types.CoreSnapshot `json:"restart-snapshot,omitempty"`
+	Files           *DownloadedFiles    `json:"files,omitempty"`
+	// MovedHomes are the paths the existing homes are moved to. They are backed up when the setup is finished.
+	MovedHomes     map[string]string `json:"moved-homes,omitempty"`
+	CompletedSteps []Step            `json:"completed-steps"`
+	UpdatedAt      time.Time         `json:"updated-at"`
+}
+
+// JournalDir returns the directory of the journal for the given visor home, e.g. ~/.vegavisor_home.vega-assistant
+func JournalDir(visorHome string) string {
+	visorHome = filepath.Clean(visorHome)
+
+	return filepath.Join(filepath.Dir(visorHome), "."+filepath.Base(visorHome)+journalDirSuffix)
+}
+
+// JournalPath returns path to the journal for the given visor home
+func JournalPath(visorHome string) string {
+	return filepath.Join(JournalDir(visorHome), journalFileName)
+}
+
+func NewJournal(settings GenerateSettings) *Journal {
+	return &Journal{
+		Settings:       settings,
+		CompletedSteps: []Step{},
+	}
+}
+
+// LoadJournal reads the journal from the visor home. It returns nil when there is no journal.
+func LoadJournal(visorHome string) (*Journal, error) {
+	journalPath := JournalPath(visorHome)
+	if !utils.FileExists(journalPath) {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(journalPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read setup journal: %w", err)
+	}
+
+	journal := &Journal{}
+	if err := json.Unmarshal(content, journal); err != nil {
+		return nil, fmt.Errorf("failed to parse setup journal(%s): %w", journalPath, err)
+	}
+
+	return journal, nil
+}
+
+// Completed returns true when the step has been already done
+func (j *Journal) Completed(step Step) bool {
+	return slices.Contains(j.CompletedSteps, step)
+}
+
+// Complete records the step and saves the journal
+func (j *Journal) Complete(step Step) error {
+	if !j.Completed(step) {
+		j.CompletedSteps = append(j.CompletedSteps, step)
+	}
+
+	return j.save()
+}
+
+// FilesDir returns the directory for the files downloaded by the setup
+func (j *Journal) FilesDir() string {
+	return filepath.Join(JournalDir(j.Settings.VisorHome), journalFilesDirName)
+}
+
+// Remove deletes the journal and the downloaded files after the setup is finished
+func (j *Journal) Remove() error {
+	if err := os.RemoveAll(JournalDir(j.Settings.VisorHome)); err != nil {
+		return fmt.Errorf("failed to remove setup journal: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveSQLPassword sets the SQL password, which is not stored in the journal. The password from the config file,
+// flags or the environment variable is used when the server accepts it, otherwise the user is asked for it.
+// The password is needed only until the data-node config is updated.
+func (j *Journal) ResolveSQLPassword(ui *input.UI, password string, nonInteractive bool) error {
+	if !j.Settings.WithDataNode() || j.Completed(StepUpdateConfigs) {
+		return nil
+	}
+
+	creds := j.Settings.SQLCredentials
+	creds.Pass = password
+	if err := creds.ResolvePassword(); err != nil {
+		return err
+	}
+
+	checkErr := vega.CheckSQLCredentials(creds)
+	if checkErr == nil {
+		j.Settings.SQLCredentials.Pass = creds.Pass
+		return nil
+	}
+
+	if nonInteractive {
+		return fmt.Errorf("the SQL password is not stored in the journal and the given one is not accepted by the server: %w", checkErr)
+	}
+
+	pass, err := AskSQLPassword(ui, creds, vega.CheckSQLCredentials)
+	if err != nil {
+		return err
+	}
+	j.Settings.SQLCredentials.Pass = pass
+
+	return nil
+}
+
+// save writes the journal next to the visor home. The SQL password is omitted, so no secret is left on the disk
+// when the setup is not finished.
+func (j *Journal) save() error {
+	journalPath := JournalPath(j.Settings.VisorHome)
+	if err := os.MkdirAll(filepath.Dir(journalPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create setup journal directory: %w", err)
+	}
+
+	j.UpdatedAt = time.Now()
+	saved := *j
+	saved.Settings.SQLCredentials.Pass = ""
+	content, err := json.MarshalIndent(saved, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal setup journal: %w", err)
+	}
+
+	if err := os.WriteFile(journalPath, content, journalFilePermission); err != nil {
+		return fmt.Errorf("failed to write setup journal(%s): %w", journalPath, err)
+	}
+
+	return nil
+}
@@ -4,19 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
-	"strings"
-	"time"
 
-	pg "github.com/go-pg/pg/v11"
 	"github.com/tcnksm/go-input"
 	"go.uber.org/zap"
-	"golang.org/x/mod/semver"
-
-	"github.com/pelletier/go-toml"
 
 	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/service/node"
 	"github.com/daniel1302/vega-assistant/types"
 	"github.com/daniel1302/vega-assistant/uilib"
 	"github.com/daniel1302/vega-assistant/utils"
@@ -27,6 +22,13 @@ import (
 type (
 	State       int
 	StartupMode string
+	NodeType    string
+)
+
+const (
+	NodeTypeDataNode NodeType = "data-node"
+	// NodeTypeFullNode is the core only node without the data-node and PostgreSQL
+	NodeTypeFullNode NodeType = "full-node"
 )
 
 const (
@@ -57,20 +59,37 @@ type StateMachine struct {
 }
 
 type GenerateSettings struct {
-	Mode StartupMode
-
-	NonInteractive              bool   `toml:"non-interactive"`
-	DataRetention               string `toml:"data-retention"`
-	VisorHome                   string `toml:"visor-home"`
-	VegaHome                    string `toml:"vega-home"`
-	TendermintHome              string `toml:"tendermint-home"`
-	DataNodeHome                string `toml:"data-node-home"`
-	VisorBinaryVersion          string
-	VegaBinaryVersion           string
-	VegaChainId                 string
+	Mode     StartupMode `toml:"mode"`
+	NodeType NodeType    `toml:"-"`
+
+	NonInteractive              bool                 `toml:"non-interactive"`
+	DataRetention               string               `toml:"data-retention"`
+	VisorHome                   string               `toml:"visor-home"`
+	VegaHome                    string               `toml:"vega-home"`
+	TendermintHome              string               `toml:"tendermint-home"`
+	DataNodeHome                string               `toml:"data-node-home"`
+	VisorBinaryVersion          string               `toml:"-"`
+	VegaBinaryVersion           string               `toml:"-"`
+	VegaChainId                 string               `toml:"-"`
 	NetworkHistoryMinBlockCount int                  `toml:"network-history-min-block-count"`
 	RemoveExistingFiles         bool                 `toml:"remove-existing-file"`
+	PreserveIdentity            bool                 `toml:"preserve-identity"`
 	SQLCredentials              types.SQLCredentials `toml:"sql-credentials"`
+	Backup                      backup.Settings      `toml:"backup"`
+	// HomeActions are actions for the existing homes selected by the user. The Backup.Action is used for other homes.
+	// They are saved in the config file, so the replayed setup treats the homes in the same way.
+	HomeActions node.HomeActions `toml:"home-actions,omitempty"`
+
+	// SaveConfigPath is the file the final answers are saved to. Empty value means the user is asked about it.
+	SaveConfigPath     string       `toml:"-" json:"-"`
+	SaveConfigPassword PasswordMode `toml:"-" json:"-"`
+	// RequestedVegaVersion is the release selected with the --vega-version flag instead of the network version
+	RequestedVegaVersion string `toml:"-" json:"-"`
+}
+
+// WithDataNode returns true when the data-node should be set up together with the core node
+func (settings GenerateSettings) WithDataNode() bool {
+	return settings.NodeType != NodeTypeFullNode
 }
 
 func DefaultGenerateSettings() *GenerateSettings {
@@ -82,6 +101,8 @@ func DefaultGenerateSettings() *GenerateSettings {
 		TendermintHome:              filepath.Join(utils.CurrentUserHomePath(), "tendermint_home"),
 		RemoveExistingFiles:         false,
 		NetworkHistoryMinBlockCount: 100,
+		Backup:                      backup.DefaultSettings(),
+		HomeActions:                 node.HomeActions{},
 
 		SQLCredentials: types.SQLCredentials{
 			Host:         "localhost",
@@ -93,18 +114,64 @@ func DefaultGenerateSettings() *GenerateSettings {
 	}
 }
 
-func ReadGeneratorSettingsFromFile(filePath string) (*GenerateSettings, error) {
-	tomlTree, err := toml.LoadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config file: %w", err)
+// Validate checks the settings loaded from the config file and flags
+func (settings GenerateSettings) Validate() error {
+	if settings.Mode != StartFromBlock0 && settings.Mode != StartFromNetworkHistory {
+		return fmt.Errorf("invalid startup mode %q: must be %s or %s", settings.Mode, StartFromBlock0, StartFromNetworkHistory)
+	}
+
+	if err := utils.ValidateNotEmpty(map[string]string{
+		"visor-home":      settings.VisorHome,
+		"vega-home":       settings.VegaHome,
+		"tendermint-home": settings.TendermintHome,
+	}); err != nil {
+		return err
+	}
+
+	if err := settings.Backup.Validate(); err != nil {
+		return err
+	}
+
+	for home, action := range settings.HomeActions {
+		if err := (backup.Settings{Action: action, Dir: settings.Backup.Dir}).Validate(); err != nil {
+			return fmt.Errorf("invalid home-actions entry for %s: %w", home, err)
+		}
+	}
+
+	if !settings.WithDataNode() {
+		return nil
+	}
+
+	if settings.NetworkHistoryMinBlockCount < 0 {
+		return fmt.Errorf("network-history-min-block-count cannot be negative")
 	}
 
-	result := &GenerateSettings{}
-	if err := tomlTree.Unmarshal(result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
+	if settings.DataRetention != "" && !vega.IsRetentionPolicyValid(settings.DataRetention) {
+		return fmt.Errorf("invalid data-retention %q", settings.DataRetention)
 	}
 
-	return result, nil
+	if err := utils.ValidateNotEmpty(map[string]string{
+		"sql-credentials.host":    settings.SQLCredentials.Host,
+		"sql-credentials.user":    settings.SQLCredentials.User,
+		"sql-credentials.db-name": settings.SQLCredentials.DatabaseName,
+	}); err != nil {
+		return err
+	}
+
+	if err := utils.ValidatePort("sql-credentials.port", settings.SQLCredentials.Port); err != nil {
+		return err
+	}
+
+	if settings.NonInteractive && settings.SQLCredentials.Pass == "" && settings.SQLCredentials.PassEnv == "" {
+		return fmt.Errorf("sql-credentials.pass or sql-credentials.pass-env must be set in the non-interactive mode")
+	}
+
+	switch settings.SaveConfigPassword {
+	case "", SavePasswordOmit, SavePasswordEnv, SavePasswordPlain:
+		return nil
+	}
+
+	return fmt.Errorf("invalid SQL password mode %q for the saved config: expected %s, %s or %s", settings.SaveConfigPassword, SavePasswordOmit, SavePasswordEnv, SavePasswordPlain)
 }
 
 func NewStateMachine(logger *zap.SugaredLogger, config GenerateSettings) StateMachine {
@@ -132,14 +199,16 @@ STATE_RUN:
 			if state.Settings.NonInteractive {
 				state.logger.Info("NonInteractive: Using %s mode", state.Settings.Mode)
 			} else {
-				mode, err := SelectStartupMode(ui, state.Settings.Mode)
+				mode, err := SelectStartupMode(ui, state.Settings.Mode, state.Settings.WithDataNode())
 				if err != nil {
 					return fmt.Errorf("failed selecting startup mode: %w", err)
 				}
 				state.Settings.Mode = *mode
 			}
 
-			if state.Settings.Mode == StartFromNetworkHistory {
+			if !state.Settings.WithDataNode() {
+				state.CurrentState = StateSelectVisorHome
+			} else if state.Settings.Mode == StartFromNetworkHistory {
 				state.CurrentState = StateSelectHowManyBlockToSync
 			} else {
 				state.CurrentState = SelectDataRetention
@@ -198,24 +267,8 @@ STATE_RUN:
 			}
 
 		case StateExistingVisorHome:
-			if state.Settings.NonInteractive {
-				if !state.Settings.RemoveExistingFiles {
-					return fmt.Errorf("cannot remove existing visor home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different vegavisor home in the config or remove it manually")
-				}
-				state.logger.Info("NonInteractive: Will remove vegavisor home: %s", state.Settings.VisorHome)
-			} else {
-				removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.VisorHome, uilib.AnswerYes)
-				if err != nil {
-					return fmt.Errorf("failed to get answer for remove existing visor home: %w", err)
-				}
-
-				if removeAnswer == uilib.AnswerNo {
-					return fmt.Errorf("visor home exists. You must provide different visor home or remove it")
-				}
-			}
-
-			if err := os.RemoveAll(state.Settings.VisorHome); err != nil {
-				return fmt.Errorf("failed to remove vegavisor home: %w", err)
+			if err := state.selectHomeAction(ui, "vegavisor home", state.Settings.VisorHome); err != nil {
+				return err
 			}
 
 			state.CurrentState = StateSelectVegaHome
@@ -241,24 +294,8 @@ STATE_RUN:
 			}
 
 		case StateExistingVegaHome:
-			if state.Settings.NonInteractive {
-				if !state.Settings.RemoveExistingFiles {
-					return fmt.Errorf("cannot remove existing vega home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different vega home in the config or remove it manually")
-				}
-				state.logger.Infof("NonInteractive: Will remove vega home: %s", state.Settings.VegaHome)
-			} else {
-				removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.VegaHome, uilib.AnswerYes)
-				if err != nil {
-					return fmt.Errorf("failed to get answer for remove existing vega home: %w", err)
-				}
-
-				if removeAnswer == uilib.AnswerNo {
-					return fmt.Errorf("vega home exists. You must provide different vega home or remove it")
-				}
-			}
-
-			if err := os.RemoveAll(state.Settings.VegaHome); err != nil {
-				return fmt.Errorf("failed to remove vega home: %w", err)
+			if err := state.selectHomeAction(ui, "vega home", state.Settings.VegaHome); err != nil {
+				return err
 			}
 
 			state.CurrentState = StateSelectTendermintHome
@@ -281,29 +318,18 @@ STATE_RUN:
 			}
 
 		case StateExistingTendermintHome:
-			if state.Settings.NonInteractive {
-				if !state.Settings.RemoveExistingFiles {
-					return fmt.Errorf("cannot remove existing tendermint home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different tendermint home in the config or remove it manually")
-				}
-				state.logger.Infof("NonInteractive: Will remove vega home: %s", state.Settings.VegaHome)
-			} else {
-				removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.TendermintHome, uilib.AnswerYes)
-				if err != nil {
-					return fmt.Errorf("failed to get answer for remove existing tendermint home: %w", err)
-				}
-
-				if removeAnswer == uilib.AnswerNo {
-					return fmt.Errorf("tendermint home exists. You must provide different tendermint home or remove it")
-				}
-			}
-
-			if err := os.RemoveAll(state.Settings.TendermintHome); err != nil {
-				return fmt.Errorf("failed to remove tendermint home: %w", err)
+			if err := state.selectHomeAction(ui, "tendermint home", state.Settings.TendermintHome); err != nil {
+				return err
 			}
 
 			state.CurrentState = StateGetSQLCredentials
 
 		case StateGetSQLCredentials:
+			if !state.Settings.WithDataNode() {
+				state.CurrentState = StateCheckLatestVersion
+				continue
+			}
+
 			if state.Settings.NonInteractive {
 				state.logger.Infof(
 					"NonInteractive: Using provided SQL settings: User(%s), Password(***), Host(%s), Port(%d), DbName(%s)",
@@ -313,7 +339,7 @@ STATE_RUN:
 					state.Settings.SQLCredentials.DatabaseName,
 				)
 
-				if err := checkSQLCredentials(state.Settings.SQLCredentials); err != nil {
+				if err := vega.CheckSQLCredentials(state.Settings.SQLCredentials); err != nil {
 					return fmt.Errorf("failed to check sql credentials: %w", err)
 				}
 
@@ -321,7 +347,7 @@ STATE_RUN:
 				continue
 			}
 
-			sqlCredentials, err := AskSQLCredentials(ui, state.Settings.SQLCredentials, checkSQLCredentials)
+			sqlCredentials, err := AskSQLCredentials(ui, state.Settings.SQLCredentials, vega.CheckSQLCredentials)
 			if err != nil {
 				return fmt.Errorf("failed getting sql credentials: %w", err)
 			}
@@ -331,32 +357,26 @@ STATE_RUN:
 		case StateCheckLatestVersion:
 			statisticsResponse, err := apiClient.Statistics(context.Background())
 			if err != nil {
-				return fmt.Errorf("failed to get response for the /statistics endpoint from the mainnet servers: %w", err)
+				return fmt.Errorf("failed to get response for the /statistics endpoint from the %s servers: %w", networkConfig.Name, err)
 			}
 
-			if state.Settings.Mode == StartFromBlock0 {
-				state.Settings.VegaBinaryVersion = networkConfig.GenesisVersion
-				state.Settings.VisorBinaryVersion = networkConfig.LowestVisorVersion
-			} else {
-				releaseVersion := statisticsResponse.AppVersion
-
-				for _, binaryOverride := range networkConfig.BinariesOverride {
-					if binaryOverride.OldVersion == releaseVersion && statisticsResponse.BlockHeight >= binaryOverride.Block {
-						releaseVersion = binaryOverride.NewVersion
-					}
-				}
-
-				state.Settings.VegaBinaryVersion = releaseVersion
-				state.Settings.VisorBinaryVersion = statisticsResponse.AppVersion
+			vegaVersion, visorVersion, err := node.Versions(statisticsResponse, networkConfig, state.Settings.RequestedVegaVersion, state.Settings.Mode == StartFromBlock0)
+			if err != nil {
+				return fmt.Errorf("failed to get binaries versions: %w", err)
 			}
 
+			state.Settings.VegaBinaryVersion = vegaVersion
+			state.Settings.VisorBinaryVersion = visorVersion
 			state.Settings.VegaChainId = statisticsResponse.ChainID
 			state.CurrentState = StateSummary
 
 		case StateSummary:
-			printSummary(state.Settings)
+			printSummary(state.Settings, networkConfig.Name)
 
 			if state.Settings.NonInteractive {
+				if err := state.saveConfig(ui); err != nil {
+					return err
+				}
 				state.logger.Info("NonInteractive: Moving to installation steps")
 
 				break STATE_RUN
@@ -372,49 +392,32 @@ STATE_RUN:
 				break
 			}
 
+			if err := state.saveConfig(ui); err != nil {
+				return err
+			}
+
 			break STATE_RUN
 		}
 	}
 	return nil
 }
 
-func checkSQLCredentials(creds types.SQLCredentials) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	db := pg.Connect(&pg.Options{
-		Addr:     fmt.Sprintf("%s:%d", creds.Host, creds.Port),
-		User:     creds.User,
-		Password: creds.Pass,
-		Database: creds.DatabaseName,
-	})
-	defer db.Close(ctx)
-
-	var n int
-	_, err := db.QueryOne(ctx, pg.Scan(&n), "SELECT 1")
-	if err != nil {
-		return err
-	}
-
-	var timescaleVersion string
-	_, err = db.QueryOne(
-		ctx,
-		pg.Scan(&timescaleVersion),
-		`SELECT COALESCE(installed_version, default_version) AS extversion FROM pg_available_extensions WHERE name = 'timescaledb' LIMIT 1;`,
+// selectHomeAction asks what to do with the existing home. The selected action is executed by the generator.
+func (state *StateMachine) selectHomeAction(ui *input.UI, name, homePath string) error {
+	actions, err := node.SelectHomeAction(
+		state.logger,
+		ui,
+		state.Settings.NonInteractive,
+		state.Settings.RemoveExistingFiles,
+		state.Settings.HomeActions,
+		state.Settings.Backup.Action,
+		name,
+		homePath,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to check timescale extension version: %w", err)
-	}
-
-	if !strings.HasPrefix(timescaleVersion, "v") {
-		timescaleVersion = fmt.Sprintf("v%s", timescaleVersion)
-	}
-
-	if semver.Compare(timescaleVersion, "v2.8.0") != 0 {
-		return fmt.Errorf(
-			"Vega support only timescale v2.8.0. Installed version is %s",
-			timescaleVersion,
-		)
+		return err
 	}
+	state.Settings.HomeActions = actions
 
 	return nil
 }
@@ -2,6 +2,7 @@ package datanode
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,8 +14,8 @@ import (
 	"github.com/daniel1302/vega-assistant/vega"
 )
 
-func SelectStartupMode(ui *input.UI, defaultValue StartupMode) (*StartupMode, error) {
-	const msg = `How do you want to start your data-node?
+func SelectStartupMode(ui *input.UI, defaultValue StartupMode, withDataNode bool) (*StartupMode, error) {
+	msg := `How do you want to start your data-node?
 
   - Starting from block 0 - Starts the node from the genesis binary, replays all the blocks and 
                             does all of the protocol upgrades automatically.
@@ -25,6 +26,17 @@ func SelectStartupMode(ui *input.UI, defaultValue StartupMode) (*StartupMode, er
                                     informations from the running network. 
         * It takes up to several minutes.
         * No historical data is available on your node.`
+	if !withDataNode {
+		msg = `How do you want to start your full node?
+
+  - Starting from block 0 - Starts the node from the genesis binary, replays all the blocks and
+                            does all of the protocol upgrades automatically.
+        * Depending on network age it can takes up to several days to catch your node up.
+
+  - Starting from network history - Start the node from latest binary and restore the state from
+                                    the snapshot served by the network peers.
+        * It takes up to several minutes.`
+	}
 	response, err := ui.Select(
 		msg,
 		[]string{string(StartFromBlock0), string(StartFromNetworkHistory)},
@@ -93,7 +105,8 @@ func AskSQLCredentials(
 		err error
 	)
 
-	fmt.Println("PostgreSQL server must be running and you MUST install the TimescaleDB v2.8.0")
+	fmt.Printf("PostgreSQL server must be running and you MUST install the TimescaleDB v%s\n", vega.TimescaleVersion)
+	fmt.Println("You can create the database and the extension on the existing server with the `vega-assistant setup postgresql --existing` command")
 	for {
 		dbHost, err = ui.Ask("PostgreSQL host for the data-node", &input.Options{
 			Default:  defaultValue.Host,
@@ -201,34 +214,66 @@ func AskSQLCredentials(
 	}, nil
 }
 
-func printSummary(settings GenerateSettings) {
-	fmt.Println("\n Summary:\n")
+// AskSQLPassword asks for the password of the given SQL user until the server accepts it
+func AskSQLPassword(
+	ui *input.UI,
+	creds types.SQLCredentials,
+	checkFunc func(types.SQLCredentials) error,
+) (string, error) {
+	for {
+		pass, err := ui.Ask(fmt.Sprintf("PostgreSQL password for the %s user at %s:%d", creds.User, creds.Host, creds.Port), &input.Options{
+			Required: true,
+			Loop:     true,
+			Mask:     true,
+		})
+		if err != nil {
+			return "", types.NewInputError(fmt.Errorf("failed to ask for database password: %w", err))
+		}
+
+		creds.Pass = pass
+		if err := checkFunc(creds); err != nil {
+			fmt.Printf("Cannot connect to the data base with given password: %s\n", err.Error())
+			continue
+		}
+
+		return pass, nil
+	}
+}
+
+func printSummary(settings GenerateSettings, networkName string) {
+	fmt.Print("\n Summary:\n\n")
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
 	tbl := table.New("Parameter", "Value")
 	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("Network", networkName)
 	if settings.Mode == StartFromBlock0 {
 		tbl.AddRow("Mode", "Start from block 0")
 	} else {
 		tbl.AddRow("Mode", "Start from Network History")
 	}
-	tbl.AddRow("Retention policy", settings.DataRetention)
+	if settings.WithDataNode() {
+		tbl.AddRow("Retention policy", settings.DataRetention)
+	}
 	tbl.AddRow("Visor Home", settings.VisorHome)
 	tbl.AddRow("Vega Home", settings.VegaHome)
 	tbl.AddRow("Tendermint Home", settings.TendermintHome)
-	tbl.AddRow("SQL Host", settings.SQLCredentials.Host)
-	tbl.AddRow("SQL Port", settings.SQLCredentials.Port)
-	tbl.AddRow("SQL User", settings.SQLCredentials.User)
-	tbl.AddRow(
-		"SQL Password",
-		fmt.Sprintf(
-			"%c***%c",
-			settings.SQLCredentials.Pass[0],
-			settings.SQLCredentials.Pass[len(settings.SQLCredentials.Pass)-1],
-		),
-	)
-	tbl.AddRow("SQL Database Name", settings.SQLCredentials.DatabaseName)
+	tbl.AddRow("Preserve Identity", settings.PreserveIdentity)
+	if settings.WithDataNode() {
+		tbl.AddRow("SQL Host", settings.SQLCredentials.Host)
+		tbl.AddRow("SQL Port", settings.SQLCredentials.Port)
+		tbl.AddRow("SQL User", settings.SQLCredentials.User)
+		tbl.AddRow(
+			"SQL Password",
+			fmt.Sprintf(
+				"%c***%c",
+				settings.SQLCredentials.Pass[0],
+				settings.SQLCredentials.Pass[len(settings.SQLCredentials.Pass)-1],
+			),
+		)
+		tbl.AddRow("SQL Database Name", settings.SQLCredentials.DatabaseName)
+	}
 	tbl.AddRow("Vega Version", settings.VegaBinaryVersion)
 	tbl.AddRow("Vega Chain ID", settings.VegaChainId)
 
@@ -236,9 +281,14 @@ func printSummary(settings GenerateSettings) {
 	fmt.Println("")
 }
 
-func PrintInstructions(visorHome string) {
+func PrintInstructions(visorHome string, nodeType NodeType) {
+	nodeName := "data node"
+	if nodeType == NodeTypeFullNode {
+		nodeName = "full node"
+	}
+
 	fmt.Printf(`
-    The data node is initialized. You can now start it with the following command:
+    The %s is initialized. You can now start it with the following command:
 
       %s/visor run --home %s
 
@@ -250,5 +300,60 @@ func PrintInstructions(visorHome string) {
 
       sudo vega-assistant setup systemd --visor-home %s
 
-    You must call the above command as a root user otherwise you will get instructions for manual systemd setup.`, visorHome, visorHome, visorHome)
+    You must call the above command as a root user otherwise you will get instructions for manual systemd setup.`, nodeName, visorHome, visorHome, visorHome)
+}
+
+// PrintPlan prints changes the setup would make on the disk
+func PrintPlan(plan *Plan) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	fmt.Print("\n Plan (dry run, nothing has been changed):\n\n")
+	tbl := table.New("Action", "Path")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, dir := range plan.RemoveDirs {
+		tbl.AddRow(fmt.Sprintf("Replace (%s)", plan.BackupActions[dir]), dir)
+	}
+	for _, dir := range plan.CreateDirs {
+		tbl.AddRow("Create", dir)
+	}
+	for _, file := range plan.RetainFiles {
+		tbl.AddRow("Retain identity", file)
+	}
+	tbl.AddRow("Download genesis", fmt.Sprintf("%s -> %s", plan.GenesisURL, plan.GenesisPath))
+	tbl.Print()
+
+	fmt.Print("\n Binaries:\n\n")
+	tbl = table.New("Binary", "Version", "Source")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, binary := range plan.Binaries {
+		tbl.AddRow(binary.Name, binary.Version, binary.Source)
+	}
+	tbl.Print()
+
+	if plan.RestartSnapshot != nil && plan.RestartSnapshot.BlockHeight != "" {
+		fmt.Printf("\n Selected snapshot for restart: block %s (%s)\n", plan.RestartSnapshot.BlockHeight, plan.RestartSnapshot.BlockHash)
+	}
+
+	for _, configFile := range plan.ConfigFiles {
+		fmt.Printf("\n The %s config (%s):\n\n", configFile.Name, configFile.Path)
+		tbl = table.New("Parameter", "Value")
+		tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+
+		keys := make([]string, 0, len(configFile.Values))
+		for key := range configFile.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := configFile.Values[key]
+			if strings.HasSuffix(key, "Password") {
+				value = "***"
+			}
+			tbl.AddRow(key, value)
+		}
+		tbl.Print()
+	}
+	fmt.Println("")
 }
@@ -0,0 +1,310 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+const systemdServiceFilePath = "/lib/systemd/system/vegavisor.service"
+
+type CheckResult struct {
+	Name    string
+	Status  Status
+	Message string
+	Hint    string
+}
+
+// NodeHomes are the homes of the installed node. The vega and tendermint homes are read from
+// the run-config.toml of the current version.
+type NodeHomes struct {
+	VisorHome      string
+	VegaHome       string
+	TendermintHome string
+	WithDataNode   bool
+}
+
+type Doctor struct {
+	homes   NodeHomes
+	results []CheckResult
+}
+
+func NewDoctor(visorHome string) *Doctor {
+	return &Doctor{
+		homes: NodeHomes{VisorHome: visorHome},
+	}
+}
+
+// Run executes all the checks and returns their results
+func (d *Doctor) Run() []CheckResult {
+	if !d.checkVisorHome() {
+		return d.results
+	}
+
+	d.checkRunConfig()
+	if d.homes.VegaHome == "" || d.homes.TendermintHome == "" {
+		return d.results
+	}
+
+	d.checkVisorConfig()
+	d.checkCoreConfig()
+	d.checkTendermintConfig()
+	if d.homes.WithDataNode {
+		d.checkDataNodeConfig()
+	}
+	d.checkOwners()
+
+	return d.results
+}
+
+func (d *Doctor) add(name string, status Status, message, hint string) {
+	d.results = append(d.results, CheckResult{
+		Name:    name,
+		Status:  status,
+		Message: message,
+		Hint:    hint,
+	})
+}
+
+func (d *Doctor) checkVisorHome() bool {
+	visorHome := d.homes.VisorHome
+	if !utils.IsDir(visorHome) {
+		d.add("Visor home", StatusFail, fmt.Sprintf("%s does not exist", visorHome), "Provide correct --visor-home or setup the node with the vega-assistant setup command")
+		return false
+	}
+	d.add("Visor home", StatusPass, visorHome, "")
+
+	d.checkBinary("Visor binary", filepath.Join(visorHome, "visor"))
+
+	currentPath := filepath.Join(visorHome, "current")
+	target, err := filepath.EvalSymlinks(currentPath)
+	if err != nil {
+		d.add("Current symlink", StatusFail, fmt.Sprintf("%s is missing or broken: %s", currentPath, err.Error()), fmt.Sprintf("Point the %s symlink to the directory of the running vega version", currentPath))
+		return false
+	}
+	d.add("Current symlink", StatusPass, fmt.Sprintf("%s -> %s", currentPath, target), "")
+
+	d.checkBinary("Vega binary", filepath.Join(currentPath, "vega"))
+
+	return true
+}
+
+func (d *Doctor) checkBinary(name, binaryPath string) {
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		d.add(name, StatusFail, fmt.Sprintf("%s does not exist", binaryPath), "Setup the node again or copy the binary from the github release")
+		return
+	}
+
+	if info.Mode()&0o111 == 0 {
+		d.add(name, StatusFail, fmt.Sprintf("%s is not executable", binaryPath), fmt.Sprintf("Run: chmod +x %s", binaryPath))
+		return
+	}
+
+	version, err := utils.ExecuteBinary(binaryPath, []string{"version"}, nil)
+	if err != nil {
+		d.add(name, StatusFail, fmt.Sprintf("%s cannot be executed: %s", binaryPath, err.Error()), "Make sure the binary is built for your operating system and architecture")
+		return
+	}
+
+	d.add(name, StatusPass, strings.TrimSpace(string(version)), "")
+}
+
+var (
+	homeArgRegex           = regexp.MustCompile(`"--home",\s*"([^"]+)"`)
+	tendermintHomeArgRegex = regexp.MustCompile(`"--tendermint-home",\s*"([^"]+)"`)
+)
+
+func (d *Doctor) checkRunConfig() {
+	runConfigPath := filepath.Join(d.homes.VisorHome, "current", "run-config.toml")
+	content, err := os.ReadFile(runConfigPath)
+	if err != nil {
+		d.add("Run config", StatusFail, fmt.Sprintf("failed to read %s: %s", runConfigPath, err.Error()), "Setup the node again, the run-config.toml describes how visor starts vega")
+		return
+	}
+
+	if _, err := toml.LoadBytes(content); err != nil {
+		d.add("Run config", StatusFail, fmt.Sprintf("%s is not valid toml: %s", runConfigPath, err.Error()), "Fix the syntax of the run-config.toml")
+		return
+	}
+
+	if match := homeArgRegex.FindSubmatch(content); match != nil {
+		d.homes.VegaHome = string(match[1])
+	}
+	if match := tendermintHomeArgRegex.FindSubmatch(content); match != nil {
+		d.homes.TendermintHome = string(match[1])
+	}
+	d.homes.WithDataNode = strings.Contains(string(content), "[data_node]")
+
+	if d.homes.VegaHome == "" || d.homes.TendermintHome == "" {
+		d.add("Run config", StatusFail, fmt.Sprintf("%s has no --home or --tendermint-home argument for vega", runConfigPath), "Add the vega and tendermint homes to the vega.binary.args")
+		return
+	}
+
+	d.add("Run config", StatusPass, runConfigPath, "")
+}
+
+func (d *Doctor) checkVisorConfig() {
+	configPath := filepath.Join(d.homes.VisorHome, vegacmd.VegavisorConfigPath)
+	config, ok := d.loadConfig("Visor config", configPath)
+	if !ok {
+		return
+	}
+
+	if enabled, _ := config.Get("autoInstall.enabled").(bool); !enabled {
+		d.add("Visor auto install", StatusWarn, "automatic upgrades are disabled", fmt.Sprintf("Set autoInstall.enabled = true in %s or prepare the binaries for every protocol upgrade manually", configPath))
+		return
+	}
+	d.add("Visor auto install", StatusPass, "enabled", "")
+}
+
+func (d *Doctor) checkCoreConfig() {
+	configPath := filepath.Join(d.homes.VegaHome, vegacmd.CoreConfigPath)
+	config, ok := d.loadConfig("Core config", configPath)
+	if !ok {
+		return
+	}
+
+	if startHeight, _ := config.Get("Snapshot.StartHeight").(int64); startHeight != -1 {
+		d.add("Core snapshot", StatusWarn, fmt.Sprintf("Snapshot.StartHeight is %d, the node restarts from this snapshot", startHeight), "Run: vega-assistant setup post-start")
+		return
+	}
+	d.add("Core snapshot", StatusPass, "node restarts from the latest local snapshot", "")
+}
+
+func (d *Doctor) checkTendermintConfig() {
+	genesisPath := filepath.Join(d.homes.TendermintHome, vegacmd.GenesisPath)
+	if !utils.FileExists(genesisPath) {
+		d.add("Genesis", StatusFail, fmt.Sprintf("%s does not exist", genesisPath), "Download genesis.json of your network into the tendermint home")
+	} else {
+		d.add("Genesis", StatusPass, genesisPath, "")
+	}
+
+	configPath := filepath.Join(d.homes.TendermintHome, vegacmd.TenderminConfigPath)
+	config, ok := d.loadConfig("Tendermint config", configPath)
+	if !ok {
+		return
+	}
+
+	if enabled, _ := config.Get("statesync.enable").(bool); enabled {
+		d.add("Tendermint statesync", StatusWarn, "statesync is still enabled", "Run: vega-assistant setup post-start, once the node is moving blocks forward")
+	} else {
+		d.add("Tendermint statesync", StatusPass, "disabled", "")
+	}
+
+	seeds, _ := config.Get("p2p.seeds").(string)
+	persistentPeers, _ := config.Get("p2p.persistent_peers").(string)
+	seedMode, _ := config.Get("p2p.seed_mode").(bool)
+	if seeds == "" && persistentPeers == "" && !seedMode {
+		d.add("Tendermint peers", StatusFail, "no seeds and no persistent peers", "Run: vega-assistant network update and setup the node again or set p2p.seeds manually")
+		return
+	}
+	d.add("Tendermint peers", StatusPass, fmt.Sprintf("%d seeds, %d persistent peers", countList(seeds), countList(persistentPeers)), "")
+}
+
+func (d *Doctor) checkDataNodeConfig() {
+	configPath := filepath.Join(d.homes.VegaHome, vegacmd.DataNodeConfigPath)
+	config, ok := d.loadConfig("Data-node config", configPath)
+	if !ok {
+		return
+	}
+
+	if wipe, _ := config.Get("SQLStore.WipeOnStartup").(bool); wipe {
+		d.add("Data-node wipe on startup", StatusFail, "the database is wiped on every restart", "Run: vega-assistant setup post-start")
+	} else {
+		d.add("Data-node wipe on startup", StatusPass, "disabled", "")
+	}
+
+	if autoInit, _ := config.Get("AutoInitialiseFromNetworkHistory").(bool); autoInit {
+		d.add("Data-node network history init", StatusWarn, "AutoInitialiseFromNetworkHistory is still enabled", "Run: vega-assistant setup post-start")
+	}
+
+	port, _ := config.Get("SQLStore.ConnectionConfig.Port").(int64)
+	creds := types.SQLCredentials{
+		Host:         getString(config, "SQLStore.ConnectionConfig.Host"),
+		Port:         int(port),
+		User:         getString(config, "SQLStore.ConnectionConfig.Username"),
+		Pass:         getString(config, "SQLStore.ConnectionConfig.Password"),
+		DatabaseName: getString(config, "SQLStore.ConnectionConfig.Database"),
+	}
+	if err := vega.CheckSQLCredentials(creds); err != nil {
+		d.add("PostgreSQL", StatusFail, err.Error(), fmt.Sprintf("Check the SQLStore.ConnectionConfig in %s and make sure PostgreSQL with TimescaleDB is running", configPath))
+		return
+	}
+	d.add("PostgreSQL", StatusPass, fmt.Sprintf("%s@%s:%d/%s", creds.User, creds.Host, creds.Port, creds.DatabaseName), "")
+}
+
+func (d *Doctor) checkOwners() {
+	visorOwner, _, err := utils.GetFileOwner(d.homes.VisorHome)
+	if err != nil {
+		d.add("Files owner", StatusWarn, err.Error(), "")
+		return
+	}
+
+	for _, home := range []string{d.homes.VegaHome, d.homes.TendermintHome} {
+		owner, _, err := utils.GetFileOwner(home)
+		if err != nil {
+			d.add("Files owner", StatusFail, err.Error(), fmt.Sprintf("Make sure %s exists", home))
+			return
+		}
+
+		if owner != visorOwner {
+			d.add("Files owner", StatusFail, fmt.Sprintf("%s is owned by %s, but visor home by %s", home, owner, visorOwner), fmt.Sprintf("Run: sudo chown -R %s %s", visorOwner, home))
+			return
+		}
+	}
+	d.add("Files owner", StatusPass, fmt.Sprintf("all homes owned by %s", visorOwner), "")
+
+	content, err := os.ReadFile(systemdServiceFilePath)
+	if err != nil {
+		return
+	}
+
+	match := regexp.MustCompile(`(?m)^User=(.+)$`).FindSubmatch(content)
+	if match == nil || strings.TrimSpace(string(match[1])) != visorOwner {
+		d.add("Systemd user", StatusFail, fmt.Sprintf("%s does not run visor as %s", systemdServiceFilePath, visorOwner), fmt.Sprintf("Run: sudo vega-assistant setup systemd --visor-home %s", d.homes.VisorHome))
+		return
+	}
+	d.add("Systemd user", StatusPass, visorOwner, "")
+}
+
+func (d *Doctor) loadConfig(name, configPath string) (*toml.Tree, bool) {
+	config, err := toml.LoadFile(configPath)
+	if err != nil {
+		d.add(name, StatusFail, fmt.Sprintf("failed to load %s: %s", configPath, err.Error()), "Setup the node again or fix the config file")
+		return nil, false
+	}
+	d.add(name, StatusPass, configPath, "")
+
+	return config, true
+}
+
+func getString(config *toml.Tree, key string) string {
+	value, _ := config.Get(key).(string)
+	return value
+}
+
+func countList(value string) int {
+	if value == "" {
+		return 0
+	}
+
+	return len(strings.Split(value, ","))
+}
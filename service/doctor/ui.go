@@ -0,0 +1,48 @@
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+func PrintResults(results []CheckResult) {
+	fmt.Print("\n Diagnostics:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Check", "Status", "Details")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, result := range results {
+		tbl.AddRow(result.Name, formatStatus(result.Status), result.Message)
+	}
+	tbl.Print()
+	fmt.Println("")
+
+	hints := false
+	for _, result := range results {
+		if result.Hint == "" || result.Status == StatusPass {
+			continue
+		}
+		if !hints {
+			fmt.Print(" How to fix:\n\n")
+			hints = true
+		}
+		fmt.Printf("  - %s: %s\n", result.Name, result.Hint)
+	}
+	if hints {
+		fmt.Println("")
+	}
+}
+
+func formatStatus(status Status) string {
+	switch status {
+	case StatusPass:
+		return color.GreenString("PASS")
+	case StatusWarn:
+		return color.YellowString("WARN")
+	}
+
+	return color.RedString("FAIL")
+}
@@ -0,0 +1,184 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// GenesisVersionName is the name of the visor directory for the binary used to start the network from block 0
+const GenesisVersionName = "genesis"
+
+// Versions returns vega and visor versions required to start the node. The genesis versions are returned
+// when starting from block 0, otherwise the requested vega version (the --vega-version flag) or the versions
+// taken from the network statistics.
+func Versions(
+	statistics *types.VegaStatistics,
+	networkConfig network.NetworkConfig,
+	requestedVegaVersion string,
+	fromGenesis bool,
+) (string, string, error) {
+	if fromGenesis {
+		if networkConfig.GenesisVersion == "" {
+			return "", "", fmt.Errorf("starting from block 0 is not supported for the %s network: genesis version is unknown", networkConfig.Name)
+		}
+
+		return networkConfig.GenesisVersion, networkConfig.LowestVisorVersion, nil
+	}
+
+	if requestedVegaVersion != "" {
+		return requestedVegaVersion, statistics.AppVersion, nil
+	}
+
+	releaseVersion := statistics.AppVersion
+	for _, binaryOverride := range networkConfig.BinariesOverride {
+		if binaryOverride.OldVersion == releaseVersion && statistics.BlockHeight >= binaryOverride.Block {
+			releaseVersion = binaryOverride.NewVersion
+		}
+	}
+
+	return releaseVersion, statistics.AppVersion, nil
+}
+
+// BinarySource describes the binary to install and where it is taken from
+type BinarySource struct {
+	Name    string
+	Version string
+	Source  string
+}
+
+// LocateBinaries finds the vega and visor binaries to install without downloading them
+func LocateBinaries(
+	networkConfig network.NetworkConfig,
+	downloadOptions github.DownloadOptions,
+	vegaVersion, visorVersion string,
+) ([]BinarySource, error) {
+	result := []BinarySource{}
+	for _, binary := range []struct {
+		artifactType github.ArtifactType
+		version      string
+	}{
+		{artifactType: github.ArtifactVega, version: vegaVersion},
+		{artifactType: github.ArtifactVisor, version: visorVersion},
+	} {
+		source, err := github.LocateArtifact(networkConfig.Repository, binary.version, binary.artifactType, downloadOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate %s binary: %w", binary.artifactType, err)
+		}
+
+		result = append(result, BinarySource{
+			Name:    string(binary.artifactType),
+			Version: binary.version,
+			Source:  source,
+		})
+	}
+
+	return result, nil
+}
+
+// DownloadBinaries downloads and verifies vega and visor binaries to the output directory and
+// returns paths to the vega and visor binaries
+func DownloadBinaries(
+	logger *zap.SugaredLogger,
+	networkConfig network.NetworkConfig,
+	downloadOptions github.DownloadOptions,
+	vegaVersion, visorVersion, outputDir string,
+) (string, string, error) {
+	downloadOptions.Logger = logger
+	if downloadOptions.Verification.Skip {
+		logger.Info("Artifact verification is disabled. Downloaded binaries are not verified")
+	}
+	if downloadOptions.Offline {
+		logger.Infof("Offline mode enabled. Binaries are taken from the cache(%s) or the local directory(%s)", github.ArtifactsCacheRoot(), downloadOptions.LocalDir)
+	}
+
+	logger.Info("Downloading vega binary")
+	vegaBinaryPath, err := github.DownloadArtifact(
+		networkConfig.Repository,
+		vegaVersion,
+		outputDir,
+		github.ArtifactVega,
+		downloadOptions,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download vega binary: %w", err)
+	}
+	logger.Infof("Vega downloaded to %s", vegaBinaryPath)
+
+	logger.Info("Downloading visor binary")
+	visorBinaryPath, err := github.DownloadArtifact(
+		networkConfig.Repository,
+		visorVersion,
+		outputDir,
+		github.ArtifactVisor,
+		downloadOptions,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download visor binary: %w", err)
+	}
+	logger.Infof("Visor downloaded to %s", visorBinaryPath)
+
+	logger.Info("Checking binaries versions")
+	vegaBinaryVersion, err := utils.ExecuteBinary(vegaBinaryPath, []string{"version"}, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check vega version: %w", err)
+	}
+	logger.Infof("Vega version is %s", vegaBinaryVersion)
+	visorBinaryVersion, err := utils.ExecuteBinary(visorBinaryPath, []string{"version"}, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check visor version: %w", err)
+	}
+	logger.Infof("Visor version is %s", visorBinaryVersion)
+
+	return vegaBinaryPath, visorBinaryPath, nil
+}
+
+// CopyBinaries copies binaries to the visor home and points the `current` symlink to the given version
+func CopyBinaries(
+	logger *zap.SugaredLogger,
+	visorHome, version string,
+	vegaBinaryPath, visorBinaryPath string,
+) error {
+	vegavisorDstFilePath := filepath.Join(visorHome, "visor")
+	logger.Infof("Copying vegavisor from %s to %s", visorBinaryPath, vegavisorDstFilePath)
+	if err := utils.CopyFile(visorBinaryPath, vegavisorDstFilePath); err != nil {
+		return fmt.Errorf("failed to copy visor binary: %w", err)
+	}
+	logger.Info("Visor binary copied")
+
+	vegaDstFilePath := filepath.Join(visorHome, version, "vega")
+	logger.Infof("Copying vega from %s to %s", vegaBinaryPath, vegaDstFilePath)
+	if err := utils.CopyFile(vegaBinaryPath, vegaDstFilePath); err != nil {
+		return fmt.Errorf("failed to copy vega binary: %w", err)
+	}
+	logger.Info("Vega binary copied")
+
+	versionDirectory := filepath.Join(visorHome, version)
+	currentDirectory := filepath.Join(visorHome, "current")
+	// The symlink may be left by the interrupted setup
+	if _, err := os.Lstat(currentDirectory); err == nil {
+		if err := os.Remove(currentDirectory); err != nil {
+			return fmt.Errorf("failed to remove existing symlink %s: %w", currentDirectory, err)
+		}
+	}
+
+	logger.Infof("Creating symlink from %s to %s", versionDirectory, currentDirectory)
+	if err := os.Symlink(versionDirectory, currentDirectory); err != nil {
+		return fmt.Errorf(
+			"failed to create symlink from %s to %s: %w",
+			versionDirectory,
+			currentDirectory,
+			err,
+		)
+	}
+	logger.Info("Symlink created")
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package node
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// TendermintConfig returns the tendermint p2p and statesync parameters common for all node types
+func TendermintConfig(seeds, persistentPeers, rpcServers []string) map[string]interface{} {
+	return map[string]interface{}{
+		"p2p.seeds":              strings.Join(seeds, ","),
+		"p2p.persistent_peers":   strings.Join(persistentPeers, ","),
+		"p2p.pex":                true,
+		"statesync.enable":       false,
+		"statesync.rpc_servers":  strings.Join(rpcServers, ","),
+		"statesync.trust_period": "672h0m0s",
+	}
+}
+
+// EnableStateSync enables the tendermint statesync from the given snapshot
+func EnableStateSync(tendermintConfig map[string]interface{}, snapshot *types.CoreSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("no selected snapshot for restart")
+	}
+
+	if snapshot.BlockHash == "" {
+		return fmt.Errorf("cannot enable statesync when selected snapshot is empty")
+	}
+
+	trustHeight, err := strconv.Atoi(snapshot.BlockHeight)
+	if err != nil {
+		return fmt.Errorf("failed to convert trust block height from string to int: %w", err)
+	}
+
+	tendermintConfig["statesync.enable"] = true
+	tendermintConfig["statesync.trust_height"] = trustHeight
+	tendermintConfig["statesync.trust_hash"] = snapshot.BlockHash
+
+	return nil
+}
+
+// VisorConfig returns the vegavisor parameters to automatically install upgrades from the given repository
+func VisorConfig(repository string) map[string]interface{} {
+	return map[string]interface{}{
+		"maxNumberOfFirstConnectionRetries": 43200,
+		"autoInstall.enabled":               true,
+		"autoInstall.repositoryOwner":       strings.Split(repository, "/")[0],
+		"autoInstall.repository":            strings.Split(repository, "/")[1],
+		"autoInstall.asset.name": fmt.Sprintf(
+			"vega-%s-%s.zip",
+			runtime.GOOS,
+			runtime.GOARCH,
+		),
+		"autoInstall.asset.binaryName": "vega",
+	}
+}
+
+// ConfigFile describes new values for the config file of the node component
+type ConfigFile struct {
+	Name   string
+	Path   string
+	Values map[string]interface{}
+}
+
+// UpdateConfigFile puts new values into the given toml config file
+func UpdateConfigFile(logger *zap.SugaredLogger, name, configPath string, newValues map[string]interface{}) error {
+	logger.Infof("Updating %s config(%s). New parameters: %v", name, configPath, newValues)
+	if err := utils.UpdateConfig(configPath, "toml", newValues); err != nil {
+		return fmt.Errorf("failed to update the %s config: %w", name, err)
+	}
+	logger.Infof("The %s config updated", name)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// PrepareVisorHome creates directory for the given version in the visor home and writes run-config.toml into it
+func PrepareVisorHome(logger *zap.SugaredLogger, visorHome string, runConfig vegacmd.VisorRunConfig) error {
+	runConfigDirPath := filepath.Join(visorHome, runConfig.Version)
+
+	logger.Infof("Preparing %s folder for vega", runConfigDirPath)
+	if err := os.MkdirAll(runConfigDirPath, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+	logger.Infof("Folder %s created", runConfigDirPath)
+
+	runConfigPath := filepath.Join(runConfigDirPath, "run-config.toml")
+	logger.Infof("Preparing run-config toml file in %s", runConfigPath)
+	runConfigContent, err := vegacmd.TemplateVisorRunConfig(runConfig)
+	if err != nil {
+		return fmt.Errorf("failed to generate run-config.toml from template: %w", err)
+	}
+	if err := os.WriteFile(runConfigPath, []byte(runConfigContent), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write run-config.toml in %s: %w", runConfigPath, err)
+	}
+	logger.Infof("The run-config.toml file saved in %s", runConfigPath)
+
+	return nil
+}
+
+// HomeActions are actions for the existing homes selected by the user, the default backup action is used for other homes
+type HomeActions map[string]backup.Action
+
+// Action returns the action selected for the home or the default action
+func (actions HomeActions) Action(homePath string, defaultAction backup.Action) backup.Action {
+	if action, ok := actions[homePath]; ok {
+		return action
+	}
+
+	return defaultAction
+}
+
+// SelectHomeAction asks what to do with the existing home and records the answer in the returned actions.
+// The home is not touched here, the generator applies the action in the homes transaction once the user
+// confirms the setup and all the files are downloaded.
+func SelectHomeAction(
+	logger *zap.SugaredLogger,
+	ui *input.UI,
+	nonInteractive, removeExistingFiles bool,
+	actions HomeActions,
+	defaultAction backup.Action,
+	name, homePath string,
+) (HomeActions, error) {
+	if actions == nil {
+		actions = HomeActions{}
+	}
+
+	if nonInteractive {
+		if !removeExistingFiles {
+			return nil, fmt.Errorf("cannot remove existing %s: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different %s in the config or remove it manually", name, name)
+		}
+		logger.Infof("NonInteractive: Existing %s(%s) will be replaced, backup action: %s", name, homePath, actions.Action(homePath, defaultAction))
+
+		return actions, nil
+	}
+
+	action, err := backup.AskAction(ui, homePath, actions.Action(homePath, defaultAction))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answer for existing %s: %w", name, err)
+	}
+	actions[homePath] = action
+
+	return actions, nil
+}
+
+// ExistingHomes returns the unique homes which already exist
+func ExistingHomes(homes ...string) []string {
+	result := []string{}
+	for _, home := range homes {
+		if utils.FileExists(home) && !slices.Contains(result, home) {
+			result = append(result, home)
+		}
+	}
+
+	return result
+}
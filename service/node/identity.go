@@ -0,0 +1,251 @@
+package node
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// IdentityHome is the kind of the home the identity file belongs to
+type IdentityHome string
+
+const (
+	IdentityHomeVega       IdentityHome = "vega"
+	IdentityHomeTendermint IdentityHome = "tendermint"
+)
+
+// identityPaths are files and directories, relative to the home, which identify the node in the network
+var identityPaths = map[IdentityHome][]string{
+	IdentityHomeTendermint: {
+		filepath.Join("config", "node_key.json"),
+		filepath.Join("config", "priv_validator_key.json"),
+	},
+	IdentityHomeVega: {
+		filepath.Join("config", "node", "wallets.encrypted"),
+		filepath.Join("data", "node", "wallets"),
+	},
+}
+
+// nodeWalletsPath is the directory with the vega and ethereum node wallets in the vega home
+var nodeWalletsPath = filepath.Join("data", "node", "wallets")
+
+// IdentityFile is the single identity file read from the existing home
+type IdentityFile struct {
+	Home    IdentityHome `json:"home"`
+	Path    string       `json:"path"`
+	Mode    os.FileMode  `json:"mode"`
+	Content []byte       `json:"content"`
+}
+
+// Identity contains node keys and wallets read from the existing homes, so they can be
+// restored in the freshly initialized homes and the node keeps its peer ID and wallets.
+type Identity struct {
+	Files []IdentityFile `json:"files"`
+}
+
+// ReadIdentity reads the node identity from the existing vega and tendermint homes
+func ReadIdentity(vegaHome, tendermintHome string) (*Identity, error) {
+	identity := &Identity{}
+	if err := identity.Read(IdentityHomeVega, vegaHome); err != nil {
+		return nil, err
+	}
+	if err := identity.Read(IdentityHomeTendermint, tendermintHome); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// HomeHasNodeWallets returns true when the existing vega home contains the node wallets
+func HomeHasNodeWallets(vegaHome string) (bool, error) {
+	identity := &Identity{}
+	if err := identity.Read(IdentityHomeVega, vegaHome); err != nil {
+		return false, err
+	}
+
+	return identity.HasNodeWallets(), nil
+}
+
+// Read reads identity files from the given home. Files read from the same kind of home before are replaced.
+// Missing files are skipped.
+func (identity *Identity) Read(home IdentityHome, homePath string) error {
+	identity.Files = slices.DeleteFunc(identity.Files, func(file IdentityFile) bool {
+		return file.Home == home
+	})
+
+	for _, relativePath := range identityPaths[home] {
+		rootPath := filepath.Join(homePath, relativePath)
+		if !utils.FileExists(rootPath) {
+			continue
+		}
+
+		err := filepath.WalkDir(rootPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			filePath, err := filepath.Rel(homePath, path)
+			if err != nil {
+				return err
+			}
+
+			identity.Files = append(identity.Files, IdentityFile{
+				Home:    home,
+				Path:    filePath,
+				Mode:    info.Mode().Perm(),
+				Content: content,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read node identity from %s: %w", rootPath, err)
+		}
+	}
+
+	return nil
+}
+
+// Empty returns true when no identity file has been found
+func (identity *Identity) Empty() bool {
+	return identity == nil || len(identity.Files) == 0
+}
+
+// HasNodeWallets returns true when the identity contains the node wallets from the vega home
+func (identity *Identity) HasNodeWallets() bool {
+	if identity == nil {
+		return false
+	}
+
+	for _, file := range identity.Files {
+		if file.isNodeWallet() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Paths returns the paths of identity files in the given homes
+func (identity *Identity) Paths(vegaHome, tendermintHome string) []string {
+	if identity == nil {
+		return nil
+	}
+
+	homes := map[IdentityHome]string{
+		IdentityHomeVega:       vegaHome,
+		IdentityHomeTendermint: tendermintHome,
+	}
+	result := make([]string, 0, len(identity.Files))
+	for _, file := range identity.Files {
+		result = append(result, filepath.Join(homes[file.Home], file.Path))
+	}
+
+	return result
+}
+
+// Restore writes identity files into the freshly initialized homes. Files generated by the init are
+// overwritten. It returns the paths of the retained files.
+func (identity *Identity) Restore(logger *zap.SugaredLogger, vegaHome, tendermintHome string) ([]string, error) {
+	if identity.Empty() {
+		return nil, nil
+	}
+
+	// The wallets directory is replaced, so the wallets generated by the init do not mix with the retained ones
+	if identity.HasNodeWallets() {
+		walletsPath := filepath.Join(vegaHome, nodeWalletsPath)
+		if err := os.RemoveAll(walletsPath); err != nil {
+			return nil, fmt.Errorf("failed to remove generated node wallets from %s: %w", walletsPath, err)
+		}
+	}
+
+	paths := identity.Paths(vegaHome, tendermintHome)
+	for idx, file := range identity.Files {
+		if err := os.MkdirAll(filepath.Dir(paths[idx]), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", paths[idx], err)
+		}
+		if err := os.WriteFile(paths[idx], file.Content, file.Mode); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", paths[idx], err)
+		}
+		// The file generated by the init may have different permissions
+		if err := os.Chmod(paths[idx], file.Mode); err != nil {
+			return nil, fmt.Errorf("failed to set permissions for %s: %w", paths[idx], err)
+		}
+		logger.Infof("Retained node identity file %s", paths[idx])
+	}
+
+	return paths, nil
+}
+
+// VerifyNodeWalletsPassphrase checks the passphrase from the file decrypts the node wallets retained in the identity.
+// The wallets are written into the temporary vega home in the workDir, so the existing homes are not touched.
+func (identity *Identity) VerifyNodeWalletsPassphrase(vegaBinaryPath, passphraseFile, workDir string) error {
+	if !identity.HasNodeWallets() {
+		return nil
+	}
+
+	vegaHome := filepath.Join(workDir, "vega_home")
+	for _, file := range identity.Files {
+		if file.Home != IdentityHomeVega {
+			continue
+		}
+
+		filePath := filepath.Join(vegaHome, file.Path)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, file.Content, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+	}
+
+	if _, err := vegacmd.ShowNodeWallets(vegaBinaryPath, vegaHome, passphraseFile); err != nil {
+		return fmt.Errorf("the passphrase from %s cannot decrypt the retained node wallets: %w", passphraseFile, err)
+	}
+
+	return nil
+}
+
+func (file IdentityFile) isNodeWallet() bool {
+	return file.Home == IdentityHomeVega && strings.HasPrefix(file.Path, nodeWalletsPath+string(filepath.Separator))
+}
+
+// identityName returns the human readable name of the identity file
+func identityName(path string) string {
+	switch filepath.Base(path) {
+	case "node_key.json":
+		return "Tendermint node key (peer ID)"
+	case "priv_validator_key.json":
+		return "Tendermint validator key"
+	case "wallets.encrypted":
+		return "Node wallets registry"
+	}
+
+	switch filepath.Base(filepath.Dir(path)) {
+	case "vega":
+		return "Vega node wallet"
+	case "ethereum":
+		return "Ethereum node wallet"
+	}
+
+	return "Node wallet file"
+}
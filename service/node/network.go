@@ -0,0 +1,103 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+// HealthyRPCServers returns the tendermint rpc servers for the statesync. Tendermint requires
+// at least two servers, so the only healthy server is duplicated.
+func HealthyRPCServers(
+	ctx context.Context,
+	vegaApi *vegaapi.NetworkAPI,
+	networkConfig network.NetworkConfig,
+) ([]string, error) {
+	healthyTendermintRPCServers, err := vegaApi.HealthyEndpoints(ctx, networkConfig.TendermintRPCServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find healthy tendermint rpc servers: %w", err)
+	}
+
+	if len(healthyTendermintRPCServers) < 1 {
+		return nil, fmt.Errorf("there is no healthy rpc server")
+	}
+
+	if len(healthyTendermintRPCServers) == 1 {
+		healthyTendermintRPCServers = append(healthyTendermintRPCServers, healthyTendermintRPCServers[0])
+	}
+
+	return healthyTendermintRPCServers, nil
+}
+
+// TendermintSeeds returns seeds defined for the network, or asks the rpc servers
+// for their node ids when the network does not define any seed
+func TendermintSeeds(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	vegaApi *vegaapi.NetworkAPI,
+	networkConfig network.NetworkConfig,
+	rpcServers []string,
+) ([]string, error) {
+	if len(networkConfig.TendermintSeeds) > 0 {
+		return networkConfig.TendermintSeeds, nil
+	}
+
+	logger.Infof("No tendermint seeds defined for the %s network. Asking rpc servers for their node ids", networkConfig.Name)
+	tendermintSeeds, err := vegaApi.TendermintPeers(ctx, rpcServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover tendermint seeds: %w", err)
+	}
+	logger.Infof("Discovered tendermint seeds: %v", tendermintSeeds)
+
+	return tendermintSeeds, nil
+}
+
+// SelectStateSyncSnapshot selects the core snapshot to start the node with the tendermint statesync.
+// The 3-rd highest snapshot is selected because the latest snapshots may not be available on all the peers yet.
+func SelectStateSyncSnapshot(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	vegaApi *vegaapi.NetworkAPI,
+) (*types.CoreSnapshot, error) {
+	logger.Info("Fetching network snapshots")
+	snapshots, err := vegaApi.Snapshots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get core snapshots: %w", err)
+	}
+
+	snapshotList := []types.CoreSnapshot{}
+	for _, snapshot := range snapshots.CoreSnapshots.Edges {
+		if snapshot.Node.BlockHash == "" || snapshot.Node.BlockHeight == "" {
+			continue
+		}
+
+		snapshotList = append(snapshotList, snapshot.Node)
+	}
+	logger.Infof("Found %d valid snapshots", len(snapshotList))
+
+	if len(snapshotList) < 3 {
+		return nil, fmt.Errorf(
+			"not enough snapshots for restart: required at least 3 snapshots, %d got",
+			len(snapshotList),
+		)
+	}
+
+	sort.Slice(snapshotList, func(i, j int) bool {
+		iHeight, _ := strconv.Atoi(snapshotList[i].BlockHeight)
+		jHeight, _ := strconv.Atoi(snapshotList[j].BlockHeight)
+
+		return iHeight > jHeight
+	})
+
+	selectedSnapshot := snapshotList[2]
+	logger.Infof("Selected snapshot for restart at block %s", selectedSnapshot.BlockHeight)
+
+	return &selectedSnapshot, nil
+}
@@ -0,0 +1,124 @@
+package node
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// HomesTransaction moves the existing homes aside before the node is set up. When the setup fails,
+// everything created in the homes is removed and the original homes are restored.
+type HomesTransaction struct {
+	logger  *zap.SugaredLogger
+	homes   []string
+	backups map[string]string
+}
+
+// BeginHomesTransaction moves the existing homes aside. They are kept next to the homes,
+// so they are renamed within the same file system.
+func BeginHomesTransaction(logger *zap.SugaredLogger, homes ...string) (*HomesTransaction, error) {
+	transaction := &HomesTransaction{
+		logger:  logger,
+		backups: map[string]string{},
+	}
+
+	now := time.Now()
+	for _, home := range homes {
+		if slices.Contains(transaction.homes, home) {
+			continue
+		}
+
+		if !utils.FileExists(home) {
+			transaction.homes = append(transaction.homes, home)
+			continue
+		}
+
+		backupPath := backup.MoveAsidePath(home, now)
+		logger.Infof("Moving existing %s to %s", home, backupPath)
+		if err := os.Rename(home, backupPath); err != nil {
+			if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+				logger.Errorf("Failed to restore homes: %s", rollbackErr.Error())
+			}
+
+			return nil, fmt.Errorf("failed to move %s aside: %w", home, err)
+		}
+		transaction.homes = append(transaction.homes, home)
+		transaction.backups[home] = backupPath
+	}
+
+	return transaction, nil
+}
+
+// ResumeHomesTransaction returns the transaction started by the previous run, which moved the existing homes
+// to the given backup paths
+func ResumeHomesTransaction(logger *zap.SugaredLogger, backups map[string]string, homes ...string) *HomesTransaction {
+	transaction := &HomesTransaction{
+		logger:  logger,
+		backups: maps.Clone(backups),
+	}
+	if transaction.backups == nil {
+		transaction.backups = map[string]string{}
+	}
+
+	for _, home := range homes {
+		if !slices.Contains(transaction.homes, home) {
+			transaction.homes = append(transaction.homes, home)
+		}
+	}
+
+	return transaction
+}
+
+// Backups returns the paths the existing homes have been moved to
+func (t *HomesTransaction) Backups() map[string]string {
+	return maps.Clone(t.backups)
+}
+
+// Rollback removes everything created in the homes and restores the original homes
+func (t *HomesTransaction) Rollback() error {
+	var resErr error
+	for _, home := range t.homes {
+		t.logger.Infof("Rolling back changes in %s", home)
+		if err := os.RemoveAll(home); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("failed to remove %s: %w", home, err))
+			continue
+		}
+
+		backupPath, ok := t.backups[home]
+		if !ok {
+			continue
+		}
+		if err := os.Rename(backupPath, home); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("failed to restore %s from %s: %w", home, backupPath, err))
+			continue
+		}
+		t.logger.Infof("Restored %s", home)
+	}
+
+	return resErr
+}
+
+// Commit backs up the original homes moved aside at the beginning of the transaction. The action
+// for the home is taken from the actions, the default action from the backup settings is used otherwise.
+func (t *HomesTransaction) Commit(actions HomeActions, settings backup.Settings) error {
+	for _, home := range t.homes {
+		backupPath, ok := t.backups[home]
+		if !ok {
+			continue
+		}
+
+		if err := backup.HandleMoved(t.logger, home, backupPath, actions.Action(home, settings.Action), settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
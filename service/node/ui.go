@@ -0,0 +1,27 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// PrintRetainedIdentity prints the identity files copied from the previous homes
+func PrintRetainedIdentity(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	fmt.Print("\n Retained node identity:\n\n")
+	tbl := table.New("Identity", "Path")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, path := range paths {
+		tbl.AddRow(identityName(path), path)
+	}
+	tbl.Print()
+	fmt.Println("")
+}
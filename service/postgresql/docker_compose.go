@@ -3,18 +3,133 @@ package postgresql
 import (
 	"bytes"
 	"fmt"
-	"html/template"
+	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+type ComposeEngine string
+
+const (
+	ComposeEngineDocker ComposeEngine = "docker"
+	ComposeEnginePodman ComposeEngine = "podman"
 )
 
-const postgresqlTemplate = `version: '3.1'
+const (
+	DefaultPostgresqlImage = "docker.io/timescale/timescaledb:2.8.0-pg14"
+	DefaultAdminerImage    = "docker.io/library/adminer:latest"
+	DefaultExporterImage   = "quay.io/prometheuscommunity/postgres-exporter:latest"
+
+	// dataVolumeName is the named volume used when the volume path is not given
+	dataVolumeName = "pgdata"
+)
+
+// ComposeSettings describes the components of the generated docker-compose.yaml
+type ComposeSettings struct {
+	Engine ComposeEngine `toml:"engine"`
+	Image  string        `toml:"image"`
+	// VolumePath is the host directory for the PostgreSQL data. The named volume is used when it is empty.
+	VolumePath   string `toml:"volume-path"`
+	Adminer      bool   `toml:"adminer"`
+	AdminerPort  int    `toml:"adminer-port"`
+	Exporter     bool   `toml:"exporter"`
+	ExporterPort int    `toml:"exporter-port"`
+	// ExporterAddress is the host address the exporter port is published on. Metrics are available
+	// from the localhost only by default.
+	ExporterAddress string `toml:"exporter-address"`
+}
+
+func DefaultComposeSettings() ComposeSettings {
+	return ComposeSettings{
+		Engine:          ComposeEngineDocker,
+		Image:           DefaultPostgresqlImage,
+		Adminer:         false,
+		AdminerPort:     8082,
+		Exporter:        false,
+		ExporterPort:    9187,
+		ExporterAddress: "127.0.0.1",
+	}
+}
+
+// Validate checks the compose settings
+func (settings ComposeSettings) Validate() error {
+	if settings.Engine != ComposeEngineDocker && settings.Engine != ComposeEnginePodman {
+		return fmt.Errorf("invalid compose.engine %q: must be %s or %s", settings.Engine, ComposeEngineDocker, ComposeEnginePodman)
+	}
+
+	if settings.Image == "" {
+		return fmt.Errorf("compose.image cannot be empty")
+	}
+
+	if settings.VolumePath != "" && !filepath.IsAbs(settings.VolumePath) {
+		return fmt.Errorf("compose.volume-path must be an absolute path: %s", settings.VolumePath)
+	}
+
+	if settings.Adminer {
+		if err := utils.ValidatePort("compose.adminer-port", settings.AdminerPort); err != nil {
+			return err
+		}
+	}
+
+	if settings.Exporter {
+		if err := utils.ValidatePort("compose.exporter-port", settings.ExporterPort); err != nil {
+			return err
+		}
+		if net.ParseIP(settings.ExporterAddress) == nil {
+			return fmt.Errorf("invalid compose.exporter-address %q: must be an IP address", settings.ExporterAddress)
+		}
+	}
+
+	return nil
+}
+
+// composeCommand returns the binary and the arguments which run the compose for the engine. For docker,
+// the compose plugin (`docker compose`) is used when it is installed, otherwise the standalone `docker-compose`.
+func (settings ComposeSettings) composeCommand() (string, []string) {
+	if settings.Engine == ComposeEnginePodman {
+		return "podman-compose", []string{}
+	}
+
+	if _, err := utils.ExecuteBinary("docker", []string{"compose", "version"}, nil); err != nil {
+		if _, err := exec.LookPath("docker-compose"); err == nil {
+			return "docker-compose", []string{}
+		}
+	}
+
+	return "docker", []string{"compose"}
+}
+
+// UpCommand returns the command which starts the compose stack from the home. It is the same command
+// the --start flag runs.
+func (settings ComposeSettings) UpCommand() string {
+	binary, args := settings.composeCommand()
+
+	return strings.Join(append(append([]string{binary}, args...), "up", "-d"), " ")
+}
 
-services:
-  db:
-    image: timescale/timescaledb:2.8.0-pg14
+// upCommandArgs returns the binary and arguments which start the compose stack from the given file
+func (settings ComposeSettings) upCommandArgs(composeFilePath string) (string, []string) {
+	binary, args := settings.composeCommand()
+
+	return binary, append(args, "-f", composeFilePath, "up", "-d")
+}
+
+// composeComponent is the single service in the docker-compose.yaml
+type composeComponent struct {
+	name     string
+	template string
+}
+
+const postgresqlComponentTemplate = `  db:
+    image: {{.Compose.Image}}
     restart: always
     environment:
       POSTGRES_USER: {{.Username}}
@@ -22,40 +137,67 @@ services:
       POSTGRES_PASSWORD: {{.Password}}
     command: [
       "postgres",
-      "-c", "max_connections=50",
       "-c", "log_destination=stderr",
-      "-c", "work_mem=5MB",
       "-c", "huge_pages=off",
       "-c", "shared_memory_type=sysv",
       "-c", "dynamic_shared_memory_type=sysv",
-      "-c", "shared_buffers=2GB",
-      "-c", "temp_buffers=5MB",
+{{- range .Parameters}}
+      "-c", "{{.Name}}={{.Value}}",
+{{- end}}
     ]
     ports:
       - {{.Port}}:5432
-    volumes: 
-      - pgdata:/var/lib/postgresql/data
+    volumes:
+      - {{.DataVolume}}:/var/lib/postgresql/data{{.VolumeOptions}}
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U {{.Username}} -d {{.DbName}}"]
+      interval: 10s
+      timeout: 5s
+      retries: 5
+`
 
-  # Adminer can be used for debugging. We recommend dissabling it for production
+const adminerComponentTemplate = `  # Adminer can be used for debugging. It is available only from the localhost
   adminer:
-    image: adminer
+    image: {{.AdminerImage}}
     restart: always
+    depends_on:
+      - db
     ports:
-      - 8082:8080
+      - 127.0.0.1:{{.Compose.AdminerPort}}:8080
+`
 
+const exporterComponentTemplate = `  # Prometheus metrics of the PostgreSQL server
+  postgres-exporter:
+    image: {{.ExporterImage}}
+    restart: always
+    depends_on:
+      - db
+    environment:
+      DATA_SOURCE_NAME: "postgresql://{{.Username}}:{{.Password}}@db:5432/{{.DbName}}?sslmode=disable"
+    ports:
+      - "{{.ExporterBinding}}:9187"
+`
 
-volumes:
-  pgdata:
-    driver: local`
+// composeComponents returns the services enabled in the settings
+func composeComponents(settings ComposeSettings) []composeComponent {
+	components := []composeComponent{
+		{name: "db", template: postgresqlComponentTemplate},
+	}
+
+	if settings.Adminer {
+		components = append(components, composeComponent{name: "adminer", template: adminerComponentTemplate})
+	}
 
-func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSettings) error {
+	if settings.Exporter {
+		components = append(components, composeComponent{name: "postgres-exporter", template: exporterComponentTemplate})
+	}
+
+	return components
+}
+
+func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSettings, tuning *Tuning) error {
 	logger.Info("Templating docker-compose.yaml file")
-	composerContent, err := templatePostgresqlDockerCompose(
-		settings.PostgresqlUsername,
-		settings.PostgresqlDatabase,
-		settings.PostgresqlPassword,
-		settings.PostgresqlPort,
-	)
+	composerContent, err := templatePostgresqlDockerCompose(settings, tuning.Parameters)
 	if err != nil {
 		return fmt.Errorf("failed to template docker-compose.yaml file: %w", err)
 	}
@@ -71,6 +213,13 @@ func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSetti
 	}
 	logger.Info("Home directory created")
 
+	if settings.Compose.VolumePath != "" {
+		logger.Infof("Creating directory for the PostgreSQL data(%s)", settings.Compose.VolumePath)
+		if err := os.MkdirAll(settings.Compose.VolumePath, 0o700); err != nil {
+			return fmt.Errorf("failed to create directory for the PostgreSQL data(%s): %w", settings.Compose.VolumePath, err)
+		}
+	}
+
 	dockerComposeFilePath := filepath.Join(settings.Home, "docker-compose.yaml")
 	logger.Infof("Writing docker-compose file to %s", dockerComposeFilePath)
 
@@ -85,28 +234,65 @@ func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSetti
 	return nil
 }
 
-func templatePostgresqlDockerCompose(
-	username, dbName, pass string,
-	port int,
-) (string, error) {
-	tmpl := template.Must(template.New("docker-compose.yaml").Parse(postgresqlTemplate))
+func templatePostgresqlDockerCompose(settings GeneratorSettings, parameters []TuningParameter) (string, error) {
+	values := struct {
+		Username        string
+		DbName          string
+		Password        string
+		Port            int
+		Parameters      []TuningParameter
+		Compose         ComposeSettings
+		DataVolume      string
+		VolumeOptions   string
+		AdminerImage    string
+		ExporterImage   string
+		ExporterBinding string
+	}{
+		Username:      settings.PostgresqlUsername,
+		DbName:        settings.PostgresqlDatabase,
+		Password:      settings.PostgresqlPassword,
+		Port:          settings.PostgresqlPort,
+		Parameters:    parameters,
+		Compose:       settings.Compose,
+		DataVolume:    dataVolumeName,
+		AdminerImage:  DefaultAdminerImage,
+		ExporterImage: DefaultExporterImage,
+		// IPv6 addresses must be wrapped in brackets in the port definition
+		ExporterBinding: net.JoinHostPort(settings.Compose.ExporterAddress, strconv.Itoa(settings.Compose.ExporterPort)),
+	}
+	if settings.Compose.VolumePath != "" {
+		values.DataVolume = settings.Compose.VolumePath
+		// Podman runs with SELinux enabled on most of the distributions, the bind mount must be relabeled
+		if settings.Compose.Engine == ComposeEnginePodman {
+			values.VolumeOptions = ":Z"
+		}
+	}
 
 	var buff bytes.Buffer
-	if err := tmpl.Execute(&buff, struct {
-		Username string
-		DbName   string
-		Password string
-		Port     int
-	}{
-		Username: username,
-		DbName:   dbName,
-		Password: pass,
-		Port:     port,
-	}); err != nil {
-		return "", fmt.Errorf("failed to template run-config.toml: %w", err)
+	buff.WriteString("version: '3.1'\n\nservices:\n")
+	for idx, component := range composeComponents(settings.Compose) {
+		if idx > 0 {
+			buff.WriteString("\n")
+		}
+
+		tmpl, err := template.New(component.name).Parse(component.template)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template for the %s service: %w", component.name, err)
+		}
+		if err := tmpl.Execute(&buff, values); err != nil {
+			return "", fmt.Errorf("failed to template the %s service: %w", component.name, err)
+		}
 	}
 
-	return buff.String(), nil
+	if settings.Compose.VolumePath == "" {
+		buff.WriteString(strings.Join([]string{
+			"",
+			"volumes:",
+			fmt.Sprintf("  %s:", dataVolumeName),
+			"    driver: local",
+			"",
+		}, "\n"))
+	}
 
-	return "", nil
+	return buff.String(), nil
 }
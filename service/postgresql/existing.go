@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/uilib"
+	"github.com/daniel1302/vega-assistant/vega"
+)
+
+// ProvisionExistingServer creates the role, the database and the TimescaleDB extension for the data-node
+// on the existing PostgreSQL server and checks the data-node can connect with the created credentials.
+// The role and the database may be shared with other clients, so the existing ones are updated only when
+// the user confirms it or the update-existing setting is given.
+func ProvisionExistingServer(logger *zap.SugaredLogger, ui *input.UI, settings GeneratorSettings) error {
+	superuser := settings.SuperuserCredentials()
+	if err := superuser.ResolvePassword(); err != nil {
+		return err
+	}
+
+	creds := settings.Credentials()
+	roleExists, databaseExists, err := vega.ExistingSQLObjects(superuser, creds)
+	if err != nil {
+		return fmt.Errorf("failed to check existing role and database: %w", err)
+	}
+
+	existing := []string{}
+	if roleExists {
+		existing = append(existing, fmt.Sprintf("the %s role (its password will be reset)", creds.User))
+	}
+	if databaseExists {
+		existing = append(existing, fmt.Sprintf("the %s database (its owner will be changed to %s)", creds.DatabaseName, creds.User))
+	}
+
+	updateExisting := settings.UpdateExisting
+	if len(existing) > 0 && !updateExisting {
+		if settings.NonInteractive {
+			return fmt.Errorf(
+				"%s already exist on the server: use the --update-existing flag to update them or choose a different username and database",
+				strings.Join(existing, " and "),
+			)
+		}
+
+		answer, err := uilib.AskYesNo(
+			ui,
+			fmt.Sprintf("The server already has %s. Other clients using them may stop working. Do you want to update them?", strings.Join(existing, " and ")),
+			uilib.AnswerNo,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to ask about existing role and database: %w", err)
+		}
+		if answer != uilib.AnswerYes {
+			return fmt.Errorf("existing role and database left unchanged: choose a different username and database")
+		}
+		updateExisting = true
+	}
+
+	logger.Infof("Provisioning PostgreSQL server at %s:%d", superuser.Host, superuser.Port)
+	if err := vega.ProvisionDatabase(logger, superuser, creds, updateExisting); err != nil {
+		return fmt.Errorf("failed to provision database: %w", err)
+	}
+
+	logger.Info("Checking the data-node credentials")
+	if err := vega.CheckSQLCredentials(settings.Credentials()); err != nil {
+		return fmt.Errorf("database provisioned, but the data-node cannot use it: %w", err)
+	}
+	logger.Info("The database is ready for the data-node")
+
+	return nil
+}
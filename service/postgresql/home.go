@@ -0,0 +1,38 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/service/node"
+)
+
+// PrepareHome writes the files for the selected output into the home. The existing home is moved aside first
+// and backed up with the action selected by the user only when all the files are written. When any file
+// cannot be written, the existing home is restored.
+func PrepareHome(logger *zap.SugaredLogger, settings GeneratorSettings, tuning *Tuning) error {
+	transaction, err := node.BeginHomesTransaction(logger, settings.Home)
+	if err != nil {
+		return fmt.Errorf("failed to prepare home: %w", err)
+	}
+
+	prepare := PrepareDockerComposeFile
+	if settings.Output == OutputNative {
+		prepare = PrepareNativeFiles
+	}
+
+	if err := prepare(logger, settings, tuning); err != nil {
+		if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w: rollback failed, clean the home manually: %s", err, rollbackErr.Error())
+		}
+
+		return err
+	}
+
+	if err := transaction.Commit(settings.HomeActions, settings.Backup); err != nil {
+		return fmt.Errorf("files are ready but failed to remove previous home: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/vega"
+)
+
+const (
+	nativeConfigPath    = "conf.d/vega.conf"
+	nativeHBAPath       = "pg_hba.conf"
+	nativeBootstrapPath = "bootstrap.sql"
+)
+
+const nativeConfigTemplate = `# PostgreSQL tuning for the vega data-node generated by vega-assistant.
+# Host memory: {{.MemoryMB}}MB, CPU cores: {{.CPUs}}, data retention: {{.DataRetention}}.
+# Copy it to the conf.d directory included by the postgresql.conf (include_dir = 'conf.d') and restart the server.
+
+# Merge it with the existing shared_preload_libraries when the server uses other extensions
+shared_preload_libraries = 'timescaledb'
+{{- range .Parameters}}
+{{.Name}} = '{{.Value}}'
+{{- end}}
+`
+
+const nativeHBATemplate = `# Entries for the vega data-node generated by vega-assistant.
+# Add them to the pg_hba.conf before the more general entries and reload the server.
+# TYPE  DATABASE  USER  ADDRESS  METHOD
+{{- range .Addresses}}
+host    {{$.DbName}}    {{$.Username}}    {{.}}    scram-sha-256
+{{- end}}
+`
+
+const nativeBootstrapTemplate = `-- Creates the role, the database and the TimescaleDB extension for the vega data-node.
+-- Run it as the PostgreSQL superuser: psql -U postgres -f bootstrap.sql
+SELECT 'CREATE ROLE "{{.Username}}" WITH LOGIN'
+WHERE NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = '{{.Username}}')\gexec
+ALTER ROLE "{{.Username}}" WITH LOGIN PASSWORD '{{.Password}}';
+
+SELECT 'CREATE DATABASE "{{.DbName}}" OWNER "{{.Username}}"'
+WHERE NOT EXISTS (SELECT 1 FROM pg_database WHERE datname = '{{.DbName}}')\gexec
+GRANT ALL PRIVILEGES ON DATABASE "{{.DbName}}" TO "{{.Username}}";
+
+\connect "{{.DbName}}"
+CREATE EXTENSION IF NOT EXISTS timescaledb WITH VERSION '{{.TimescaleVersion}}';
+GRANT ALL ON SCHEMA public TO "{{.Username}}";
+`
+
+// nativeFile is the file generated for the PostgreSQL installed from the distribution packages
+type nativeFile struct {
+	path       string
+	template   string
+	permission os.FileMode
+}
+
+// PrepareNativeFiles writes the tuning config snippet, the pg_hba.conf entries and the SQL bootstrap script
+// for the PostgreSQL server installed from the distribution packages
+func PrepareNativeFiles(logger *zap.SugaredLogger, settings GeneratorSettings, tuning *Tuning) error {
+	values := struct {
+		Username         string
+		Password         string
+		DbName           string
+		Addresses        []string
+		TimescaleVersion string
+		MemoryMB         int
+		CPUs             int
+		DataRetention    string
+		Parameters       []TuningParameter
+	}{
+		Username:         settings.PostgresqlUsername,
+		Password:         settings.PostgresqlPassword,
+		DbName:           settings.PostgresqlDatabase,
+		Addresses:        settings.HBAAddresses,
+		TimescaleVersion: vega.TimescaleVersion,
+		MemoryMB:         tuning.MemoryMB,
+		CPUs:             tuning.CPUs,
+		DataRetention:    tuning.DataRetention,
+		Parameters:       tuning.Parameters,
+	}
+
+	files := []nativeFile{
+		{path: nativeConfigPath, template: nativeConfigTemplate, permission: 0o644},
+		{path: nativeHBAPath, template: nativeHBATemplate, permission: 0o644},
+		// The script contains the password of the data-node user
+		{path: nativeBootstrapPath, template: nativeBootstrapTemplate, permission: 0o600},
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(settings.Home, file.path)
+		logger.Infof("Writing %s", filePath)
+
+		tmpl, err := template.New(file.path).Parse(file.template)
+		if err != nil {
+			return fmt.Errorf("failed to parse template for %s: %w", file.path, err)
+		}
+
+		var buff bytes.Buffer
+		if err := tmpl.Execute(&buff, values); err != nil {
+			return fmt.Errorf("failed to template %s: %w", file.path, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, buff.Bytes(), file.permission); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+		logger.Infof("The %s file created", filePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package postgresql
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
+)
+
+const (
+	// The up command pulls the images before it returns, so the server only needs time to initialize the database
+	readinessRetries    = 60
+	readinessRetryDelay = 5 * time.Second
+)
+
+// StartDockerCompose starts the compose stack from the home, waits until PostgreSQL accepts connections
+// and checks the TimescaleDB version required by the data-node
+func StartDockerCompose(logger *zap.SugaredLogger, settings GeneratorSettings) error {
+	composeFilePath := filepath.Join(settings.Home, "docker-compose.yaml")
+	binary, args := settings.Compose.upCommandArgs(composeFilePath)
+
+	logger.Infof("Starting PostgreSQL with %s %v", binary, args)
+	output, err := utils.ExecuteBinary(binary, args, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start the compose stack: %w", err)
+	}
+	if len(output) > 0 {
+		logger.Info(string(output))
+	}
+
+	creds := settings.Credentials()
+	logger.Infof("Waiting until PostgreSQL accepts connections on %s:%d", creds.Host, creds.Port)
+	if err := utils.RetryRun(readinessRetries, readinessRetryDelay, func() error {
+		return vega.PingSQL(creds)
+	}); err != nil {
+		return fmt.Errorf("PostgreSQL is not ready: %w", err)
+	}
+	logger.Info("PostgreSQL accepts connections")
+
+	logger.Info("Checking the TimescaleDB version")
+	if err := vega.CheckSQLCredentials(creds); err != nil {
+		return fmt.Errorf("PostgreSQL is running, but the data-node cannot use it: %w", err)
+	}
+	logger.Info("PostgreSQL is ready for the data-node")
+
+	return nil
+}
@@ -2,39 +2,91 @@ package postgresql
 
 import (
 	"fmt"
-	"os"
+	"net"
 	"path/filepath"
 	"regexp"
 
 	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
 
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/service/node"
+	"github.com/daniel1302/vega-assistant/types"
 	"github.com/daniel1302/vega-assistant/uilib"
 	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
 )
 
-type State int
+type (
+	State      int
+	OutputMode string
+)
+
+const (
+	// OutputDockerCompose prepares the docker-compose.yaml with the PostgreSQL server
+	OutputDockerCompose OutputMode = "docker-compose"
+	// OutputNative prepares the config snippets and the bootstrap script for the PostgreSQL installed from packages
+	OutputNative OutputMode = "native"
+)
 
 const (
-	StateGetHome State = iota
+	StateSelectOutput State = iota
+	StateGetHome
 	StateExistingHome
+	StateGetPostgresqlHost
+	StateGetSuperuser
 	StateGetPostgresqlUsername
 	StateGetPostgresqlPassword
 	StateGetPostgresqlDatabase
 	StateGetPostgresqlPort
+	StateGetDataRetention
+	StateGetComposeComponents
 	StateSummary
 )
 
 type GeneratorSettings struct {
-	Home               string
-	PostgresqlUsername string
-	PostgresqlPassword string
-	PostgresqlDatabase string
-	PostgresqlPort     int
+	NonInteractive      bool            `toml:"non-interactive"`
+	Home                string          `toml:"home"`
+	PostgresqlUsername  string          `toml:"username"`
+	PostgresqlPassword  string          `toml:"password"`
+	PostgresqlDatabase  string          `toml:"database"`
+	PostgresqlPort      int             `toml:"port"`
+	RemoveExistingFiles bool            `toml:"remove-existing-file"`
+	Backup              backup.Settings `toml:"backup"`
+	// HomeActions are actions for the existing home selected by the user. The Backup.Action is used otherwise.
+	HomeActions node.HomeActions `toml:"-"`
+
+	// DataRetention, MemoryMB and CPUs are used to tune the PostgreSQL server. Resources are detected when zero
+	DataRetention string `toml:"data-retention"`
+	MemoryMB      int    `toml:"memory-mb"`
+	CPUs          int    `toml:"cpus"`
+
+	// Output is the kind of files generated in the home
+	Output  OutputMode      `toml:"output"`
+	Compose ComposeSettings `toml:"compose"`
+	// Start the compose stack and wait until the server is ready for the data-node
+	Start bool `toml:"start"`
+	// HBAAddresses are addresses the data-node connects from, used for the pg_hba.conf entries in the native output
+	HBAAddresses []string `toml:"hba-addresses"`
+
+	// Existing server is provisioned instead of preparing the docker-compose.yaml
+	Existing             bool   `toml:"existing"`
+	PostgresqlHost       string `toml:"host"`
+	SuperuserName        string `toml:"superuser"`
+	SuperuserPassword    string `toml:"superuser-password"`
+	SuperuserPasswordEnv string `toml:"superuser-password-env"`
+	SuperuserDatabase    string `toml:"superuser-database"`
+	// UpdateExisting allows to reset the password of the existing role and take over the existing database
+	UpdateExisting bool `toml:"update-existing"`
 }
 
 type StateMachine struct {
 	Settings     GeneratorSettings
 	CurrentState State
+	// Tuning is computed for the docker-compose.yaml before the summary
+	Tuning *Tuning
+
+	logger *zap.SugaredLogger
 }
 
 func DefaultGeneratorSettings() GeneratorSettings {
@@ -44,13 +96,101 @@ func DefaultGeneratorSettings() GeneratorSettings {
 		PostgresqlPassword: "vega",
 		PostgresqlDatabase: "vega",
 		PostgresqlPort:     5432,
+		Backup:             backup.DefaultSettings(),
+		HomeActions:        node.HomeActions{},
+		DataRetention:      "standard",
+		Output:             OutputDockerCompose,
+		Compose:            DefaultComposeSettings(),
+		HBAAddresses:       []string{"127.0.0.1/32", "::1/128"},
+		PostgresqlHost:     "localhost",
+		SuperuserName:      "postgres",
+		SuperuserDatabase:  "postgres",
+	}
+}
+
+// Credentials returns the credentials the data-node uses to connect to the database
+func (settings GeneratorSettings) Credentials() types.SQLCredentials {
+	return types.SQLCredentials{
+		Host:         settings.PostgresqlHost,
+		Port:         settings.PostgresqlPort,
+		User:         settings.PostgresqlUsername,
+		Pass:         settings.PostgresqlPassword,
+		DatabaseName: settings.PostgresqlDatabase,
 	}
 }
 
-func NewStateMachine() StateMachine {
+// SuperuserCredentials returns the credentials used to provision the existing server
+func (settings GeneratorSettings) SuperuserCredentials() types.SQLCredentials {
+	return types.SQLCredentials{
+		Host:         settings.PostgresqlHost,
+		Port:         settings.PostgresqlPort,
+		User:         settings.SuperuserName,
+		Pass:         settings.SuperuserPassword,
+		PassEnv:      settings.SuperuserPasswordEnv,
+		DatabaseName: settings.SuperuserDatabase,
+	}
+}
+
+// Validate checks the settings loaded from the config file and flags
+func (settings GeneratorSettings) Validate() error {
+	if settings.Home == "" {
+		return fmt.Errorf("home cannot be empty")
+	}
+
+	for _, value := range []string{settings.PostgresqlUsername, settings.PostgresqlPassword, settings.PostgresqlDatabase} {
+		if err := validatePostgreSQLCredentialsString(value); err != nil {
+			return err
+		}
+	}
+
+	if err := settings.Backup.Validate(); err != nil {
+		return err
+	}
+
+	if !vega.IsRetentionPolicyValid(settings.DataRetention) {
+		return fmt.Errorf("invalid data-retention %q", settings.DataRetention)
+	}
+
+	if settings.MemoryMB < 0 || settings.CPUs < 0 {
+		return fmt.Errorf("memory-mb and cpus cannot be negative")
+	}
+
+	if settings.Output != OutputDockerCompose && settings.Output != OutputNative {
+		return fmt.Errorf("invalid output %q: must be %s or %s", settings.Output, OutputDockerCompose, OutputNative)
+	}
+
+	if err := settings.Compose.Validate(); err != nil {
+		return err
+	}
+
+	if settings.Start && (settings.Existing || settings.Output != OutputDockerCompose) {
+		return fmt.Errorf("start is supported only for the %s output", OutputDockerCompose)
+	}
+
+	for _, address := range settings.HBAAddresses {
+		if _, _, err := net.ParseCIDR(address); err != nil {
+			return fmt.Errorf("invalid hba-addresses entry %q: %w", address, err)
+		}
+	}
+
+	if settings.Existing {
+		if err := utils.ValidateNotEmpty(map[string]string{
+			"host":               settings.PostgresqlHost,
+			"superuser":          settings.SuperuserName,
+			"superuser-database": settings.SuperuserDatabase,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return utils.ValidatePort("port", settings.PostgresqlPort)
+}
+
+func NewStateMachine(logger *zap.SugaredLogger, settings GeneratorSettings) StateMachine {
 	return StateMachine{
-		CurrentState: StateGetHome,
-		Settings:     DefaultGeneratorSettings(),
+		logger:       logger,
+		CurrentState: StateSelectOutput,
+		Settings:     settings,
 	}
 }
 
@@ -58,36 +198,102 @@ func (state *StateMachine) Run(ui *input.UI) error {
 STATE_RUN:
 	for {
 		switch state.CurrentState {
+		case StateSelectOutput:
+			if state.Settings.Existing {
+				// Nothing is written to the disk for the existing server
+				state.CurrentState = StateGetPostgresqlHost
+				continue
+			}
+
+			if !state.Settings.NonInteractive {
+				output, err := SelectOutput(ui, state.Settings.Output)
+				if err != nil {
+					return fmt.Errorf("failed to select output: %w", err)
+				}
+				state.Settings.Output = output
+			}
+			state.CurrentState = StateGetHome
+
 		case StateGetHome:
-			answer, err := uilib.AskPath(ui, "Home for docker-compose", state.Settings.Home)
-			if err != nil {
-				return fmt.Errorf("failed to ask for home: %w", err)
+			if !state.Settings.NonInteractive {
+				answer, err := uilib.AskPath(ui, fmt.Sprintf("Home for the %s files", state.Settings.Output), state.Settings.Home)
+				if err != nil {
+					return fmt.Errorf("failed to ask for home: %w", err)
+				}
+				state.Settings.Home = answer
 			}
 
-			state.Settings.Home = answer
-			if utils.FileExists(answer) {
+			if utils.FileExists(state.Settings.Home) {
 				state.CurrentState = StateExistingHome
 			} else {
 				state.CurrentState = StateGetPostgresqlUsername
 			}
 
 		case StateExistingHome:
-			removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.Home, uilib.AnswerYes)
+			// The selected action is executed when the files are prepared, after the summary is confirmed
+			actions, err := node.SelectHomeAction(
+				state.logger,
+				ui,
+				state.Settings.NonInteractive,
+				state.Settings.RemoveExistingFiles,
+				state.Settings.HomeActions,
+				state.Settings.Backup.Action,
+				"home",
+				state.Settings.Home,
+			)
 			if err != nil {
-				return fmt.Errorf("failed to get answer for remove existing home: %w", err)
+				return err
 			}
+			state.Settings.HomeActions = actions
 
-			if removeAnswer == uilib.AnswerNo {
-				return fmt.Errorf("the home dir exists. You must provide different home or remove it")
+			state.CurrentState = StateGetPostgresqlUsername
+
+		case StateGetPostgresqlHost:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using existing PostgreSQL server at %s:%d", state.Settings.PostgresqlHost, state.Settings.PostgresqlPort)
+				state.CurrentState = StateGetSuperuser
+				continue
 			}
 
-			if err := os.RemoveAll(state.Settings.Home); err != nil {
-				return fmt.Errorf("failed to remove home: %w", err)
+			host, err := uilib.AskString(ui, "Existing PostgreSQL server host", state.Settings.PostgresqlHost, nil)
+			if err != nil {
+				return fmt.Errorf("failed to ask for PostgreSQL host: %w", err)
 			}
+			state.Settings.PostgresqlHost = host
+			state.CurrentState = StateGetSuperuser
 
+		case StateGetSuperuser:
+			if state.Settings.NonInteractive {
+				if state.Settings.SuperuserPassword == "" && state.Settings.SuperuserPasswordEnv == "" {
+					return fmt.Errorf("superuser-password or superuser-password-env is required for the existing server in the non-interactive mode")
+				}
+				state.logger.Infof("NonInteractive: Using %s superuser to provision the server", state.Settings.SuperuserName)
+				state.CurrentState = StateGetPostgresqlUsername
+				continue
+			}
+
+			superuser, err := uilib.AskString(ui, "PostgreSQL superuser name", state.Settings.SuperuserName, nil)
+			if err != nil {
+				return fmt.Errorf("failed to ask for PostgreSQL superuser: %w", err)
+			}
+			state.Settings.SuperuserName = superuser
+
+			if state.Settings.SuperuserPassword == "" && state.Settings.SuperuserPasswordEnv == "" {
+				password, err := AskSuperuserPassword(ui, superuser)
+				if err != nil {
+					return fmt.Errorf("failed to ask for PostgreSQL superuser password: %w", err)
+				}
+				state.Settings.SuperuserPassword = password
+			}
 			state.CurrentState = StateGetPostgresqlUsername
 
 		case StateGetPostgresqlUsername:
+			if state.Settings.NonInteractive {
+				// Credentials have already been validated when the settings were loaded
+				state.CurrentState = StateSummary
+				continue
+			}
+
 			username, err := uilib.AskString(ui, "PostgreSQL username", state.Settings.PostgresqlUsername, validatePostgreSQLCredentialsString)
 			if err != nil {
 				return fmt.Errorf("failed to ask for PostgreSQL username: %w", err)
@@ -117,10 +323,48 @@ STATE_RUN:
 				return fmt.Errorf("failed to ask for PostgreSQL port: %w", err)
 			}
 			state.Settings.PostgresqlPort = port
+			state.CurrentState = StateGetDataRetention
+
+		case StateGetDataRetention:
+			if state.Settings.Existing {
+				state.CurrentState = StateSummary
+				continue
+			}
+
+			retention, err := uilib.AskString(ui, "Data retention of the data-node (standard, forever, 1 day, N days, N months, N years)", state.Settings.DataRetention, validateRetentionPolicy)
+			if err != nil {
+				return fmt.Errorf("failed to ask for data retention: %w", err)
+			}
+			state.Settings.DataRetention = retention
+			state.CurrentState = StateGetComposeComponents
+
+		case StateGetComposeComponents:
 			state.CurrentState = StateSummary
+			if state.Settings.Output != OutputDockerCompose {
+				continue
+			}
+
+			adminer, err := uilib.AskYesNo(ui, "Do you want to add Adminer (available on the localhost only) for debugging?", yesNoAnswer(state.Settings.Compose.Adminer))
+			if err != nil {
+				return fmt.Errorf("failed to ask for adminer: %w", err)
+			}
+			state.Settings.Compose.Adminer = adminer == uilib.AnswerYes
+
+			exporter, err := uilib.AskYesNo(ui, "Do you want to add the Prometheus postgres-exporter?", yesNoAnswer(state.Settings.Compose.Exporter))
+			if err != nil {
+				return fmt.Errorf("failed to ask for postgres-exporter: %w", err)
+			}
+			state.Settings.Compose.Exporter = exporter == uilib.AnswerYes
 
 		case StateSummary:
-			printSummary(state.Settings)
+			if !state.Settings.Existing {
+				state.Tuning = NewTuning(state.logger, state.Settings)
+			}
+
+			printSummary(state.Settings, state.Tuning)
+			if state.Settings.NonInteractive {
+				break STATE_RUN
+			}
 
 			answer, err := uilib.AskYesNo(ui, "Is it correct?", uilib.AnswerYes)
 			if err != nil {
@@ -128,7 +372,7 @@ STATE_RUN:
 			}
 
 			if answer == uilib.AnswerNo {
-				state.CurrentState = StateGetHome
+				state.CurrentState = StateSelectOutput
 			} else {
 				break STATE_RUN
 			}
@@ -138,12 +382,29 @@ STATE_RUN:
 	return nil
 }
 
+func yesNoAnswer(value bool) uilib.YesNoAnswer {
+	if value {
+		return uilib.AnswerYes
+	}
+
+	return uilib.AnswerNo
+}
+
+func validateRetentionPolicy(policy string) error {
+	if !vega.IsRetentionPolicyValid(policy) {
+		return fmt.Errorf("invalid retention policy %q", policy)
+	}
+
+	return nil
+}
+
 func validatePostgreSQLCredentialsString(s string) error {
 	strRegex := regexp.MustCompile(`^[A-Za-z0-9_\.-]{3,}$`)
 
 	if !strRegex.MatchString(s) {
 		return fmt.Errorf(
 			"string '%s' must contains ony digits, characters and the following chars: _.-",
+			s,
 		)
 	}
 	return nil
@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const (
+	// maxConnections is enough for the data-node connection pool and the admin tools
+	maxConnections = 50
+	// recommendedMemoryMB is the memory recommended to run the data-node together with the PostgreSQL
+	recommendedMemoryMB = 4096
+
+	// Minimum values of the memory parameters, the PostgreSQL defaults are used as the minimum
+	minSharedBuffersMB      = 128
+	minEffectiveCacheSizeMB = 512
+	minMaintenanceWorkMemMB = 64
+	minWorkMemMB            = 4
+)
+
+// retentionClass groups the data retention policies by the amount of data the data-node keeps
+type retentionClass int
+
+const (
+	retentionShort retentionClass = iota
+	retentionStandard
+	retentionLong
+)
+
+// TuningParameter is the single PostgreSQL server parameter passed to the postgres command
+type TuningParameter struct {
+	Name  string
+	Value string
+}
+
+// Tuning is the PostgreSQL configuration derived from the host resources and the data retention
+type Tuning struct {
+	MemoryMB      int
+	CPUs          int
+	DataRetention string
+	Parameters    []TuningParameter
+}
+
+// NewTuning computes the PostgreSQL parameters for the host. The memory and CPUs from the settings
+// are used when given, otherwise they are detected. On the small host the memory parameters are clamped
+// to the minimum values.
+//
+// The data-node and the vega core usually run on the same host, so the half of the memory is left for them.
+func NewTuning(logger *zap.SugaredLogger, settings GeneratorSettings) *Tuning {
+	memoryMB := settings.MemoryMB
+	if memoryMB == 0 {
+		totalMemory, err := utils.TotalMemory()
+		if err != nil {
+			logger.Warnf("Failed to detect the host memory, assuming %dMB. Provide it with the --memory-mb flag: %s", recommendedMemoryMB, err.Error())
+			totalMemory = recommendedMemoryMB * 1024 * 1024
+		}
+		memoryMB = int(totalMemory / 1024 / 1024)
+	}
+	if memoryMB < recommendedMemoryMB {
+		logger.Warnf("The host has %dMB of memory, at least %dMB is recommended to run the data-node. PostgreSQL may run out of memory", memoryMB, recommendedMemoryMB)
+	}
+
+	cpus := settings.CPUs
+	if cpus == 0 {
+		cpus = runtime.NumCPU()
+	}
+
+	postgresqlMemoryMB := memoryMB / 2
+	sharedBuffersMB := max(postgresqlMemoryMB/4, minSharedBuffersMB)
+	effectiveCacheSizeMB := max(postgresqlMemoryMB*3/4, minEffectiveCacheSizeMB)
+	maintenanceWorkMemMB := max(min(postgresqlMemoryMB/16, 2048), minMaintenanceWorkMemMB)
+	parallelWorkersPerGather := max(cpus/2, 1)
+	// Every connection may run a few sorts and hash joins at once, each of them in the parallel workers
+	workMemMB := max((postgresqlMemoryMB-sharedBuffersMB)/(maxConnections*3)/parallelWorkersPerGather, minWorkMemMB)
+	// Background workers run the retention policies, compression and continuous aggregates of the data-node
+	backgroundWorkers := max(cpus, 8)
+
+	minWALSizeMB, maxWALSizeMB := 1024, 4096
+	switch classifyRetention(settings.DataRetention) {
+	case retentionShort:
+		minWALSizeMB, maxWALSizeMB = 512, 2048
+	case retentionLong:
+		minWALSizeMB, maxWALSizeMB = 2048, 8192
+	}
+
+	return &Tuning{
+		MemoryMB:      memoryMB,
+		CPUs:          cpus,
+		DataRetention: settings.DataRetention,
+		Parameters: []TuningParameter{
+			{Name: "max_connections", Value: strconv.Itoa(maxConnections)},
+			{Name: "shared_buffers", Value: formatMemory(sharedBuffersMB)},
+			{Name: "effective_cache_size", Value: formatMemory(effectiveCacheSizeMB)},
+			{Name: "maintenance_work_mem", Value: formatMemory(maintenanceWorkMemMB)},
+			{Name: "work_mem", Value: formatMemory(workMemMB)},
+			{Name: "temp_buffers", Value: "8MB"},
+			{Name: "min_wal_size", Value: formatMemory(minWALSizeMB)},
+			{Name: "max_wal_size", Value: formatMemory(maxWALSizeMB)},
+			{Name: "wal_buffers", Value: "16MB"},
+			{Name: "checkpoint_completion_target", Value: "0.9"},
+			{Name: "max_worker_processes", Value: strconv.Itoa(cpus + backgroundWorkers + 3)},
+			{Name: "max_parallel_workers", Value: strconv.Itoa(cpus)},
+			{Name: "max_parallel_workers_per_gather", Value: strconv.Itoa(parallelWorkersPerGather)},
+			{Name: "timescaledb.max_background_workers", Value: strconv.Itoa(backgroundWorkers)},
+		},
+	}
+}
+
+// classifyRetention returns the class of the data-node retention policy, e.g. standard, forever, 1 day, 7 days, 2 months
+func classifyRetention(policy string) retentionClass {
+	switch policy {
+	case "forever":
+		return retentionLong
+	case "", "standard":
+		return retentionStandard
+	}
+
+	fields := strings.Fields(policy)
+	if len(fields) != 2 {
+		return retentionStandard
+	}
+
+	amount, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return retentionStandard
+	}
+
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "day":
+		if amount <= 7 {
+			return retentionShort
+		}
+	case "year":
+		return retentionLong
+	case "month":
+		if amount >= 6 {
+			return retentionLong
+		}
+	}
+
+	return retentionStandard
+}
+
+// formatMemory formats megabytes in the PostgreSQL format, e.g. 512MB, 4GB
+func formatMemory(megabytes int) string {
+	if megabytes >= 1024 && megabytes%1024 == 0 {
+		return fmt.Sprintf("%dGB", megabytes/1024)
+	}
+
+	return fmt.Sprintf("%dMB", megabytes)
+}
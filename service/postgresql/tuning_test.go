@@ -0,0 +1,149 @@
+package postgresql
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewTuning(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    GeneratorSettings
+		expected    map[string]string
+		wantWarning bool
+	}{
+		{
+			name:     "low-memory host is clamped to the minimum values",
+			settings: GeneratorSettings{MemoryMB: 1024, CPUs: 1},
+			expected: map[string]string{
+				"shared_buffers":                     "128MB",
+				"effective_cache_size":               "512MB",
+				"maintenance_work_mem":               "64MB",
+				"work_mem":                           "4MB",
+				"max_worker_processes":               "12",
+				"max_parallel_workers":               "1",
+				"max_parallel_workers_per_gather":    "1",
+				"timescaledb.max_background_workers": "8",
+				"min_wal_size":                       "1GB",
+				"max_wal_size":                       "4GB",
+			},
+			wantWarning: true,
+		},
+		{
+			name:     "host just below the recommended memory",
+			settings: GeneratorSettings{MemoryMB: recommendedMemoryMB - 1, CPUs: 2},
+			expected: map[string]string{
+				"shared_buffers":       "511MB",
+				"effective_cache_size": "1535MB",
+				"maintenance_work_mem": "127MB",
+			},
+			wantWarning: true,
+		},
+		{
+			name:     "host with the recommended memory",
+			settings: GeneratorSettings{MemoryMB: recommendedMemoryMB, CPUs: 2},
+			expected: map[string]string{
+				"shared_buffers":       "512MB",
+				"effective_cache_size": "1536MB",
+				"maintenance_work_mem": "128MB",
+			},
+		},
+		{
+			name:     "standard host with standard retention",
+			settings: GeneratorSettings{MemoryMB: 16384, CPUs: 8, DataRetention: "standard"},
+			expected: map[string]string{
+				"max_connections":                    "50",
+				"shared_buffers":                     "2GB",
+				"effective_cache_size":               "6GB",
+				"maintenance_work_mem":               "512MB",
+				"work_mem":                           "10MB",
+				"max_worker_processes":               "19",
+				"max_parallel_workers":               "8",
+				"max_parallel_workers_per_gather":    "4",
+				"timescaledb.max_background_workers": "8",
+				"min_wal_size":                       "1GB",
+				"max_wal_size":                       "4GB",
+			},
+		},
+		{
+			name:     "large host caps the maintenance memory",
+			settings: GeneratorSettings{MemoryMB: 262144, CPUs: 32, DataRetention: "forever"},
+			expected: map[string]string{
+				"shared_buffers":                     "32GB",
+				"effective_cache_size":               "96GB",
+				"maintenance_work_mem":               "2GB",
+				"work_mem":                           "40MB",
+				"max_worker_processes":               "67",
+				"timescaledb.max_background_workers": "32",
+				"min_wal_size":                       "2GB",
+				"max_wal_size":                       "8GB",
+			},
+		},
+		{
+			name:     "short retention",
+			settings: GeneratorSettings{MemoryMB: 16384, CPUs: 8, DataRetention: "7 days"},
+			expected: map[string]string{
+				"min_wal_size": "512MB",
+				"max_wal_size": "2GB",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			tuning := NewTuning(zap.New(core).Sugar(), tt.settings)
+
+			if tuning.MemoryMB != tt.settings.MemoryMB || tuning.CPUs != tt.settings.CPUs {
+				t.Errorf("expected %dMB and %d CPUs, got %dMB and %d CPUs", tt.settings.MemoryMB, tt.settings.CPUs, tuning.MemoryMB, tuning.CPUs)
+			}
+
+			parameters := map[string]string{}
+			for _, parameter := range tuning.Parameters {
+				parameters[parameter.Name] = parameter.Value
+			}
+			for name, expected := range tt.expected {
+				if parameters[name] != expected {
+					t.Errorf("expected %s = %s, got %s", name, expected, parameters[name])
+				}
+			}
+
+			if hasWarning := logs.Len() > 0; hasWarning != tt.wantWarning {
+				t.Errorf("expected low memory warning: %t, got: %t", tt.wantWarning, hasWarning)
+			}
+		})
+	}
+}
+
+func TestClassifyRetention(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected retentionClass
+	}{
+		{policy: "", expected: retentionStandard},
+		{policy: "standard", expected: retentionStandard},
+		{policy: "forever", expected: retentionLong},
+		{policy: "1 day", expected: retentionShort},
+		{policy: "7 days", expected: retentionShort},
+		{policy: "8 days", expected: retentionStandard},
+		{policy: "30 days", expected: retentionStandard},
+		{policy: "5 months", expected: retentionStandard},
+		{policy: "6 months", expected: retentionLong},
+		{policy: "1 year", expected: retentionLong},
+		{policy: "2 years", expected: retentionLong},
+		{policy: "2 weeks", expected: retentionStandard},
+		{policy: "seven days", expected: retentionStandard},
+		{policy: "7days", expected: retentionStandard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			if result := classifyRetention(tt.policy); result != tt.expected {
+				t.Errorf("expected class %d for '%s', got %d", tt.expected, tt.policy, result)
+			}
+		})
+	}
+}
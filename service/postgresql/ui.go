@@ -2,29 +2,125 @@ package postgresql
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
+	"github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vega"
 )
 
-func PrintInstructions(homePath string) {
+func PrintInstructions(homePath string, compose ComposeSettings) {
 	fmt.Printf(`
 
     Your setup is ready. Now you have to start postgreSQL with the following commands:
 
     cd %s;
-    docker-compose up -d;`, homePath)
+    %s;`, homePath, compose.UpCommand())
+	fmt.Println("")
+}
+
+// PrintNativeInstructions prints how to apply the files generated for the PostgreSQL installed from packages
+func PrintNativeInstructions(homePath string) {
+	fmt.Printf(`
+
+    Your setup is ready. Install PostgreSQL 14 with the TimescaleDB v%s extension and apply the generated files:
+
+    1. Copy %s to the conf.d directory of your PostgreSQL server (e.g. /etc/postgresql/14/main/conf.d/).
+    2. Add the entries from %s to the pg_hba.conf before the more general entries.
+    3. Restart the PostgreSQL server, e.g.: sudo systemctl restart postgresql
+    4. Create the database: sudo -u postgres psql -f %s`,
+		vega.TimescaleVersion,
+		filepath.Join(homePath, nativeConfigPath),
+		filepath.Join(homePath, nativeHBAPath),
+		filepath.Join(homePath, nativeBootstrapPath),
+	)
 	fmt.Println("")
 }
 
-func printSummary(settings GeneratorSettings) {
-	fmt.Println("\n Summary:\n")
+// PrintReadyInstructions prints the credentials for the data-node when the server is ready
+func PrintReadyInstructions(creds types.SQLCredentials) {
+	fmt.Printf(`
+
+    The PostgreSQL server is ready for the data-node. Use the following credentials in the vega-assistant setup data-node command:
+
+    host:     %s
+    port:     %d
+    user:     %s
+    database: %s`, creds.Host, creds.Port, creds.User, creds.DatabaseName)
+	fmt.Println("")
+}
+
+// SelectOutput asks what kind of files should be generated for the PostgreSQL server
+func SelectOutput(ui *input.UI, defaultValue OutputMode) (OutputMode, error) {
+	msg := `How do you run the PostgreSQL server?
+
+  - docker-compose - Prepare the docker-compose.yaml which starts the PostgreSQL server with the TimescaleDB extension.
+
+  - native - Prepare the config snippets and the bootstrap SQL script for the PostgreSQL server installed
+             from the distribution packages. The TimescaleDB must be installed separately.`
+	response, err := ui.Select(
+		msg,
+		[]string{string(OutputDockerCompose), string(OutputNative)},
+		&input.Options{
+			Default:  string(defaultValue),
+			Loop:     true,
+			Required: true,
+		},
+	)
+	if err != nil {
+		return "", types.NewInputError(err)
+	}
+
+	return OutputMode(response), nil
+}
+
+// AskSuperuserPassword asks for the superuser password without printing it
+func AskSuperuserPassword(ui *input.UI, superuser string) (string, error) {
+	password, err := ui.Ask(fmt.Sprintf("PostgreSQL password for the %s superuser", superuser), &input.Options{
+		Required: true,
+		Loop:     true,
+		Mask:     true,
+	})
+	if err != nil {
+		return "", types.NewInputError(err)
+	}
+
+	return password, nil
+}
+
+func printSummary(settings GeneratorSettings, tuning *Tuning) {
+	fmt.Print("\n Summary:\n\n")
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
 	tbl := table.New("Parameter", "Value")
 	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
-	tbl.AddRow("Home", settings.Home)
+	if settings.Existing {
+		tbl.AddRow("Mode", "Provision existing server")
+		tbl.AddRow("SQL Host", settings.PostgresqlHost)
+		tbl.AddRow("SQL Superuser", settings.SuperuserName)
+		tbl.AddRow("Update Existing Role And Database", settings.UpdateExisting)
+	} else {
+		tbl.AddRow("Output", settings.Output)
+		tbl.AddRow("Home", settings.Home)
+		if settings.Output == OutputNative {
+			tbl.AddRow("Allowed Addresses", strings.Join(settings.HBAAddresses, ", "))
+		} else {
+			tbl.AddRow("Compose Engine", settings.Compose.Engine)
+			tbl.AddRow("Image", settings.Compose.Image)
+			if settings.Compose.VolumePath != "" {
+				tbl.AddRow("Data Volume", settings.Compose.VolumePath)
+			} else {
+				tbl.AddRow("Data Volume", fmt.Sprintf("%s (named volume)", dataVolumeName))
+			}
+			tbl.AddRow("Adminer", componentSummary(settings.Compose.Adminer, fmt.Sprintf("127.0.0.1:%d", settings.Compose.AdminerPort)))
+			tbl.AddRow("Postgres Exporter", componentSummary(settings.Compose.Exporter, fmt.Sprintf("%s:%d", settings.Compose.ExporterAddress, settings.Compose.ExporterPort)))
+		}
+	}
 	tbl.AddRow("SQL Port", settings.PostgresqlPort)
 	tbl.AddRow("SQL User", settings.PostgresqlUsername)
 	tbl.AddRow(
@@ -36,7 +132,33 @@ func printSummary(settings GeneratorSettings) {
 		),
 	)
 	tbl.AddRow("SQL Database Name", settings.PostgresqlDatabase)
+	if tuning != nil {
+		tbl.AddRow("Data Retention", tuning.DataRetention)
+		tbl.AddRow("Host Memory", formatMemory(tuning.MemoryMB))
+		tbl.AddRow("Host CPUs", tuning.CPUs)
+	}
 
 	tbl.Print()
 	fmt.Println("")
+
+	if tuning == nil {
+		return
+	}
+
+	fmt.Print(" PostgreSQL tuning:\n\n")
+	tbl = table.New("Parameter", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, parameter := range tuning.Parameters {
+		tbl.AddRow(parameter.Name, parameter.Value)
+	}
+	tbl.Print()
+	fmt.Println("")
+}
+
+func componentSummary(enabled bool, address string) string {
+	if !enabled {
+		return "Disabled"
+	}
+
+	return fmt.Sprintf("Enabled (%s)", address)
 }
@@ -22,10 +22,9 @@ func UpdateConfig(logger *zap.SugaredLogger, vegaHome, tendermintHome string) er
 		"Snapshot.StartHeight": -1,
 	}
 
+	// The full node has no data-node config, we skip it then
 	dataNodeConfigPath := filepath.Join(vegaHome, vegacmd.DataNodeConfigPath)
-	if !utils.FileExists(dataNodeConfigPath) {
-		return fmt.Errorf("data node config(%s) does not exists", dataNodeConfigPath)
-	}
+	withDataNode := utils.FileExists(dataNodeConfigPath)
 
 	tendermintConfigPath := filepath.Join(tendermintHome, vegacmd.TenderminConfigPath)
 	if !utils.FileExists(tendermintConfigPath) {
@@ -37,19 +36,23 @@ func UpdateConfig(logger *zap.SugaredLogger, vegaHome, tendermintHome string) er
 		return fmt.Errorf("vega core config(%s) does not exists", coreConfigPath)
 	}
 
-	logger.Infof(
-		"Updating data-node config(%s). New values: %v",
-		dataNodeConfigPath,
-		dataNodeConfig,
-	)
-	if err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig); err != nil {
-		return fmt.Errorf("failed to update data node config(%s): %w", dataNodeConfigPath, err)
+	if withDataNode {
+		logger.Infof(
+			"Updating data-node config(%s). New values: %v",
+			dataNodeConfigPath,
+			dataNodeConfig,
+		)
+		if err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig); err != nil {
+			return fmt.Errorf("failed to update data node config(%s): %w", dataNodeConfigPath, err)
+		}
+		logger.Info("Data node config updated")
+	} else {
+		logger.Infof("Data node config(%s) not found. Skipping data-node config update", dataNodeConfigPath)
 	}
-	logger.Info("Data node config updated")
 
 	logger.Infof("Updating core config(%s). New values: %v", coreConfigPath, coreConfig)
 	if err := utils.UpdateConfig(coreConfigPath, "toml", coreConfig); err != nil {
-		return fmt.Errorf("failed to update core config(%s): %w", coreConfigPath)
+		return fmt.Errorf("failed to update core config(%s): %w", coreConfigPath, err)
 	}
 	logger.Info("Core config updated")
 
@@ -3,6 +3,7 @@ package poststart
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/tcnksm/go-input"
 
@@ -19,8 +20,14 @@ const (
 )
 
 type ServiceSettings struct {
-	VegaHome       string
-	TendermintHome string
+	NonInteractive bool   `toml:"non-interactive"`
+	VegaHome       string `toml:"vega-home"`
+	TendermintHome string `toml:"tendermint-home"`
+
+	// Wait for the node to catch up with the network before configs are updated
+	Wait           bool         `toml:"wait"`
+	WaitSettings   WaitSettings `toml:"wait-settings"`
+	RestartService bool         `toml:"restart-service"`
 }
 
 type StateMachine struct {
@@ -32,13 +39,46 @@ func DefaultServiceSettings() ServiceSettings {
 	return ServiceSettings{
 		VegaHome:       filepath.Join(utils.CurrentUserHomePath(), "vega_home"),
 		TendermintHome: filepath.Join(utils.CurrentUserHomePath(), "tendermint_home"),
+		WaitSettings: WaitSettings{
+			CoreREST:     "http://localhost:3003",
+			DataNodeREST: "http://localhost:3008",
+			MaxBlocksLag: 50,
+			PollInterval: 30 * time.Second,
+			Timeout:      72 * time.Hour,
+		},
+	}
+}
+
+// Validate checks the settings loaded from the config file and flags
+func (settings ServiceSettings) Validate() error {
+	if err := utils.ValidateNotEmpty(map[string]string{
+		"vega-home":       settings.VegaHome,
+		"tendermint-home": settings.TendermintHome,
+	}); err != nil {
+		return err
+	}
+
+	if !settings.Wait {
+		return nil
+	}
+
+	if settings.WaitSettings.CoreREST == "" {
+		return fmt.Errorf("wait-settings.core-url cannot be empty")
+	}
+	if settings.WaitSettings.PollInterval <= 0 {
+		return fmt.Errorf("wait-settings.poll-interval must be positive")
 	}
+	if settings.WaitSettings.Timeout < 0 {
+		return fmt.Errorf("wait-settings.wait-timeout cannot be negative")
+	}
+
+	return nil
 }
 
-func NewStateMachine() StateMachine {
+func NewStateMachine(settings ServiceSettings) StateMachine {
 	return StateMachine{
 		CurrentState: StateGetVegaHome,
-		Settings:     DefaultServiceSettings(),
+		Settings:     settings,
 	}
 }
 
@@ -47,6 +87,14 @@ STATE_RUN:
 	for {
 		switch state.CurrentState {
 		case StateGetVegaHome:
+			if state.Settings.NonInteractive {
+				if err := checkIfExists("Vega Home")(state.Settings.VegaHome); err != nil {
+					return err
+				}
+				state.CurrentState = StateGetTendermintHome
+				continue
+			}
+
 			answer, err := uilib.AskString(ui, "What is your vega home?", state.Settings.VegaHome, checkIfExists("Vega Home"))
 			if err != nil {
 				return fmt.Errorf("failed to ask for vega home: %w", err)
@@ -55,6 +103,14 @@ STATE_RUN:
 			state.CurrentState = StateGetTendermintHome
 
 		case StateGetTendermintHome:
+			if state.Settings.NonInteractive {
+				if err := checkIfExists("Tendermint Home")(state.Settings.TendermintHome); err != nil {
+					return err
+				}
+				state.CurrentState = StateSummary
+				continue
+			}
+
 			answer, err := uilib.AskString(ui, "What is your tendermint home?", state.Settings.TendermintHome, checkIfExists("Tendermint Home"))
 			if err != nil {
 				return fmt.Errorf("failed to ask for tendermint home: %w", err)
@@ -64,6 +120,10 @@ STATE_RUN:
 
 		case StateSummary:
 			printSummary(state.Settings)
+			if state.Settings.NonInteractive {
+				break STATE_RUN
+			}
+
 			answer, err := uilib.AskYesNo(ui, "Is it correct?", uilib.AnswerYes)
 			if err != nil {
 				return fmt.Errorf("failed to ask if summary correct: %w", err)
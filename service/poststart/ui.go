@@ -8,7 +8,7 @@ import (
 )
 
 func printSummary(settings ServiceSettings) {
-	fmt.Println("\n Summary:\n")
+	fmt.Print("\n Summary:\n\n")
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
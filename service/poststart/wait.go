@@ -0,0 +1,103 @@
+package poststart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+const requestTimeout = 5 * time.Second
+
+type WaitSettings struct {
+	CoreREST     string `toml:"core-url"`
+	DataNodeREST string `toml:"data-node-url"`
+	// MaxBlocksLag is the number of blocks the node may be behind the network to be considered synced
+	MaxBlocksLag uint64        `toml:"max-lag"`
+	PollInterval time.Duration `toml:"poll-interval"`
+	Timeout      time.Duration `toml:"wait-timeout"`
+}
+
+// WaitForSync polls the local node until it moves blocks forward and catches up with the network.
+// When the data-node REST is given, the data-node must catch up with the local core too.
+func WaitForSync(logger *zap.SugaredLogger, apiClient *vegaapi.NetworkAPI, settings WaitSettings) error {
+	deadline := time.Now().Add(settings.Timeout)
+	previousHeight := uint64(0)
+
+	for {
+		synced, height, err := checkSync(logger, apiClient, settings, previousHeight)
+		if err != nil {
+			logger.Infof("Node is not ready yet: %s", err.Error())
+		} else if synced {
+			return nil
+		}
+		if height > 0 {
+			previousHeight = height
+		}
+
+		if settings.Timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("node has not caught up with the network in %s", settings.Timeout)
+		}
+
+		time.Sleep(settings.PollInterval)
+	}
+}
+
+func checkSync(
+	logger *zap.SugaredLogger,
+	apiClient *vegaapi.NetworkAPI,
+	settings WaitSettings,
+	previousHeight uint64,
+) (bool, uint64, error) {
+	ctx, cancel := requestContext()
+	networkStats, err := apiClient.LatestStatistics(ctx)
+	cancel()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get network statistics: %w", err)
+	}
+
+	ctx, cancel = requestContext()
+	coreStats, err := apiClient.NodeStatistics(ctx, settings.CoreREST)
+	cancel()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get local core statistics: %w", err)
+	}
+
+	blocksLag := int64(networkStats.BlockHeight) - int64(coreStats.BlockHeight)
+	logger.Infof("Local core height: %d, network height: %d, lag: %d blocks", coreStats.BlockHeight, networkStats.BlockHeight, blocksLag)
+
+	if previousHeight == 0 || coreStats.BlockHeight <= previousHeight {
+		// We need at least two measurements to know the node is moving blocks forward
+		return false, coreStats.BlockHeight, nil
+	}
+
+	if blocksLag > int64(settings.MaxBlocksLag) {
+		return false, coreStats.BlockHeight, nil
+	}
+
+	if settings.DataNodeREST != "" {
+		ctx, cancel := requestContext()
+		dataNodeStats, err := apiClient.NodeStatistics(ctx, settings.DataNodeREST)
+		cancel()
+		if err != nil {
+			return false, coreStats.BlockHeight, fmt.Errorf("failed to get local data-node statistics: %w", err)
+		}
+
+		dataNodeLag := int64(coreStats.BlockHeight) - int64(dataNodeStats.DataNodeHeight)
+		logger.Infof("Local data-node height: %d, lag behind core: %d blocks", dataNodeStats.DataNodeHeight, dataNodeLag)
+		if dataNodeLag > int64(settings.MaxBlocksLag) {
+			return false, coreStats.BlockHeight, nil
+		}
+	}
+
+	return true, coreStats.BlockHeight, nil
+}
+
+// requestContext returns the context for the single request. Every request has its own timeout,
+// so the slow network lookup does not make the local check time out.
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
@@ -0,0 +1,254 @@
+package seednode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/node"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+type SeedNodeGenerator struct {
+	vegaApi         *vegaapi.NetworkAPI
+	userSettings    GenerateSettings
+	networkConfig   network.NetworkConfig
+	downloadOptions github.DownloadOptions
+
+	identity         *node.Identity
+	retainedIdentity []string
+}
+
+func NewSeedNodeGenerator(
+	vegaApi *vegaapi.NetworkAPI,
+	settings GenerateSettings,
+	networkConfig network.NetworkConfig,
+	downloadOptions github.DownloadOptions,
+) (*SeedNodeGenerator, error) {
+	return &SeedNodeGenerator{
+		vegaApi:         vegaApi,
+		userSettings:    settings,
+		networkConfig:   networkConfig,
+		downloadOptions: downloadOptions,
+	}, nil
+}
+
+// Run prepares the seed node and returns its address in the <node-id>@<host>:<port> format. All the lookups and
+// downloads are done before the homes are touched. When any step fails, the homes are rolled back to the state from
+// before the setup.
+func (gen *SeedNodeGenerator) Run(logger *zap.SugaredLogger) (string, error) {
+	outputDir, err := os.MkdirTemp("", "vega-assistant")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	vegaBinaryPath, visorBinaryPath, err := node.DownloadBinaries(
+		logger,
+		gen.networkConfig,
+		gen.downloadOptions,
+		gen.userSettings.VegaBinaryVersion,
+		gen.userSettings.VisorBinaryVersion,
+		outputDir,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to download binaries: %w", err)
+	}
+
+	genesisPath := filepath.Join(outputDir, "genesis.json")
+	if err := utils.DownloadFile(gen.networkConfig.GenesisURL, genesisPath); err != nil {
+		return "", fmt.Errorf("failed to download genesis: %w", err)
+	}
+
+	configFiles, err := gen.configFiles(logger)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare config files for the node: %w", err)
+	}
+
+	if gen.userSettings.PreserveIdentity {
+		gen.identity, err = node.ReadIdentity(gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	transaction, err := node.BeginHomesTransaction(logger, gen.homes()...)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare homes: %w", err)
+	}
+
+	seedAddress, err := gen.install(logger, vegaBinaryPath, visorBinaryPath, genesisPath, configFiles)
+	if err != nil {
+		logger.Infof("Setup failed: %s", err.Error())
+		if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+			return "", fmt.Errorf("%w: rollback failed, clean the homes manually: %s", err, rollbackErr.Error())
+		}
+
+		return "", err
+	}
+
+	if err := transaction.Commit(gen.userSettings.HomeActions, gen.userSettings.Backup); err != nil {
+		return "", fmt.Errorf("node is ready but failed to remove previous homes: %w", err)
+	}
+
+	return seedAddress, nil
+}
+
+// install initializes the node in the homes and puts binaries, configs and genesis in place
+func (gen *SeedNodeGenerator) install(
+	logger *zap.SugaredLogger,
+	vegaBinaryPath, visorBinaryPath, genesisPath string,
+	configFiles []node.ConfigFile,
+) (string, error) {
+	if err := gen.initNode(logger, visorBinaryPath, vegaBinaryPath); err != nil {
+		return "", fmt.Errorf("failed to init vega seed node: %w", err)
+	}
+
+	retained, err := gen.identity.Restore(logger, gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore node identity: %w", err)
+	}
+	gen.retainedIdentity = retained
+
+	runConfig := vegacmd.VisorRunConfig{
+		Version:        gen.userSettings.VegaBinaryVersion,
+		VegaHome:       gen.userSettings.VegaHome,
+		TendermintHome: gen.userSettings.TendermintHome,
+	}
+	if err := node.PrepareVisorHome(logger, gen.userSettings.VisorHome, runConfig); err != nil {
+		return "", fmt.Errorf("failed to prepare visor home: %w", err)
+	}
+
+	if err := node.CopyBinaries(logger, gen.userSettings.VisorHome, gen.userSettings.VegaBinaryVersion, vegaBinaryPath, visorBinaryPath); err != nil {
+		return "", fmt.Errorf("failed to copy binaries to visor home: %w", err)
+	}
+
+	for _, configFile := range configFiles {
+		if err := node.UpdateConfigFile(logger, configFile.Name, configFile.Path, configFile.Values); err != nil {
+			return "", fmt.Errorf("failed to update config files for the node: %w", err)
+		}
+	}
+
+	genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
+	logger.Infof("Copying genesis from %s to %s", genesisPath, genesisDestination)
+	if err := utils.CopyFile(genesisPath, genesisDestination); err != nil {
+		return "", fmt.Errorf("failed to copy genesis: %w", err)
+	}
+	logger.Info("Genesis copied")
+
+	nodeID, err := vegacmd.TendermintNodeID(vegaBinaryPath, gen.userSettings.TendermintHome)
+	if err != nil {
+		return "", fmt.Errorf("failed to get seed node id: %w", err)
+	}
+
+	return gen.userSettings.SeedAddress(nodeID), nil
+}
+
+// RetainedIdentity returns the paths of the identity files retained from the previous homes
+func (gen *SeedNodeGenerator) RetainedIdentity() []string {
+	return gen.retainedIdentity
+}
+
+// homes returns all the homes of the node
+func (gen *SeedNodeGenerator) homes() []string {
+	return []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+	}
+}
+
+func (gen *SeedNodeGenerator) initNode(
+	logger *zap.SugaredLogger,
+	visorBinary, vegaBinary string,
+) error {
+	logger.Infof("Initializing vegavisor in the %s", gen.userSettings.VisorHome)
+	if err := vegacmd.InitVisor(visorBinary, gen.userSettings.VisorHome); err != nil {
+		return fmt.Errorf(
+			"failed to initialize vegavisor in %s: %w",
+			gen.userSettings.VisorHome,
+			err,
+		)
+	}
+	logger.Info("Visor successfully initialized")
+
+	logger.Infof("Initializing tendermint in the %s", gen.userSettings.TendermintHome)
+	if err := vegacmd.InitTendermint(vegaBinary, gen.userSettings.TendermintHome); err != nil {
+		return fmt.Errorf(
+			"failed to initialize tendermint in %s: %w",
+			gen.userSettings.TendermintHome,
+			err,
+		)
+	}
+	logger.Info("Tendermint successfully initialized")
+
+	logger.Infof("Initializing vega seed node in the %s", gen.userSettings.VegaHome)
+	if err := vegacmd.InitVega(vegaBinary, gen.userSettings.VegaHome, vegacmd.VegaNodeSeed); err != nil {
+		return fmt.Errorf(
+			"failed to initialize vega seed node in %s: %w",
+			gen.userSettings.VegaHome,
+			err,
+		)
+	}
+	logger.Info("Vega seed node successfully initialized")
+
+	return nil
+}
+
+// configFiles returns new values for the core, tendermint and vegavisor config files
+func (gen *SeedNodeGenerator) configFiles(logger *zap.SugaredLogger) ([]node.ConfigFile, error) {
+	healthyTendermintRPCServers, err := node.HealthyRPCServers(context.Background(), gen.vegaApi, gen.networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tendermint rpc servers: %w", err)
+	}
+
+	tendermintSeeds, err := node.TendermintSeeds(context.Background(), logger, gen.vegaApi, gen.networkConfig, healthyTendermintRPCServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tendermint seeds: %w", err)
+	}
+
+	vegaConfig := map[string]interface{}{
+		"Snapshot.StartHeight":  -1,
+		"Broker.Socket.Enabled": false,
+	}
+
+	tendermintConfig := node.TendermintConfig(
+		tendermintSeeds,
+		gen.networkConfig.TendermintPersistentPeers,
+		healthyTendermintRPCServers,
+	)
+	// The seed node crawls the network and shares the address book with nodes connecting to it,
+	// so it accepts many short-lived inbound connections and keeps strict address book.
+	tendermintConfig["p2p.seed_mode"] = true
+	tendermintConfig["p2p.laddr"] = fmt.Sprintf("tcp://0.0.0.0:%d", gen.userSettings.P2PPort)
+	tendermintConfig["p2p.external_address"] = gen.userSettings.ExternalAddress()
+	tendermintConfig["p2p.max_num_inbound_peers"] = gen.userSettings.MaxInboundPeers
+	tendermintConfig["p2p.max_num_outbound_peers"] = gen.userSettings.MaxOutboundPeers
+	tendermintConfig["p2p.addr_book_strict"] = true
+	tendermintConfig["p2p.allow_duplicate_ip"] = false
+
+	return []node.ConfigFile{
+		{
+			Name:   "vega-core",
+			Path:   filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath),
+			Values: vegaConfig,
+		},
+		{
+			Name:   "tendermint",
+			Path:   filepath.Join(gen.userSettings.TendermintHome, vegacmd.TenderminConfigPath),
+			Values: tendermintConfig,
+		},
+		{
+			Name:   "vegavisor",
+			Path:   filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath),
+			Values: node.VisorConfig(gen.networkConfig.Repository),
+		},
+	}, nil
+}
@@ -0,0 +1,328 @@
+package seednode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/service/node"
+	"github.com/daniel1302/vega-assistant/uilib"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type State int
+
+const (
+	StateSelectVisorHome State = iota
+	StateExistingVisorHome
+	StateSelectVegaHome
+	StateExistingVegaHome
+	StateSelectTendermintHome
+	StateExistingTendermintHome
+	StateGetExternalHost
+	StateGetP2PPort
+	StateGetPeerLimits
+	StateCheckLatestVersion
+	StateSummary
+)
+
+type StateMachine struct {
+	CurrentState State
+	Settings     GenerateSettings
+
+	logger *zap.SugaredLogger
+}
+
+type GenerateSettings struct {
+	NonInteractive      bool   `toml:"non-interactive"`
+	VisorHome           string `toml:"visor-home"`
+	VegaHome            string `toml:"vega-home"`
+	TendermintHome      string `toml:"tendermint-home"`
+	ExternalHost        string `toml:"external-host"`
+	P2PPort             int    `toml:"p2p-port"`
+	MaxInboundPeers     int    `toml:"max-inbound-peers"`
+	MaxOutboundPeers    int    `toml:"max-outbound-peers"`
+	VisorBinaryVersion  string
+	VegaBinaryVersion   string
+	VegaChainId         string
+	RemoveExistingFiles bool            `toml:"remove-existing-file"`
+	PreserveIdentity    bool            `toml:"preserve-identity"`
+	Backup              backup.Settings `toml:"backup"`
+	// HomeActions are actions for the existing homes selected by the user. The Backup.Action is used for other homes.
+	HomeActions node.HomeActions `toml:"-"`
+	// RequestedVegaVersion is the release selected with the --vega-version flag instead of the network version
+	RequestedVegaVersion string `toml:"-"`
+}
+
+func DefaultGenerateSettings() *GenerateSettings {
+	return &GenerateSettings{
+		NonInteractive:      false,
+		VisorHome:           filepath.Join(utils.CurrentUserHomePath(), "vegavisor_home"),
+		VegaHome:            filepath.Join(utils.CurrentUserHomePath(), "vega_home"),
+		TendermintHome:      filepath.Join(utils.CurrentUserHomePath(), "tendermint_home"),
+		P2PPort:             26656,
+		MaxInboundPeers:     1000,
+		MaxOutboundPeers:    100,
+		RemoveExistingFiles: false,
+		Backup:              backup.DefaultSettings(),
+		HomeActions:         node.HomeActions{},
+	}
+}
+
+// Validate checks the settings loaded from the config file and flags
+func (settings GenerateSettings) Validate() error {
+	if err := utils.ValidateNotEmpty(map[string]string{
+		"visor-home":      settings.VisorHome,
+		"vega-home":       settings.VegaHome,
+		"tendermint-home": settings.TendermintHome,
+	}); err != nil {
+		return err
+	}
+
+	if err := settings.Backup.Validate(); err != nil {
+		return err
+	}
+
+	if err := utils.ValidatePort("p2p-port", settings.P2PPort); err != nil {
+		return err
+	}
+
+	if settings.MaxInboundPeers < 0 || settings.MaxOutboundPeers < 0 {
+		return fmt.Errorf("max-inbound-peers and max-outbound-peers cannot be negative")
+	}
+
+	return nil
+}
+
+// SeedAddress returns the address of the seed node in the format expected by the tendermint p2p.seeds parameter
+func (settings GenerateSettings) SeedAddress(nodeID string) string {
+	return fmt.Sprintf("%s@%s", nodeID, settings.ExternalAddress())
+}
+
+// ExternalAddress returns the host:port address other nodes use to reach the seed node
+func (settings GenerateSettings) ExternalAddress() string {
+	return net.JoinHostPort(settings.ExternalHost, strconv.Itoa(settings.P2PPort))
+}
+
+func NewStateMachine(logger *zap.SugaredLogger, config GenerateSettings) StateMachine {
+	return StateMachine{
+		logger:       logger,
+		CurrentState: StateSelectVisorHome,
+		Settings:     config,
+	}
+}
+
+func (state StateMachine) Dump() string {
+	result, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return ""
+	}
+
+	return string(result)
+}
+
+func (state *StateMachine) Run(apiClient *vegaapi.NetworkAPI, ui *input.UI, networkConfig network.NetworkConfig) error {
+STATE_RUN:
+	for {
+		switch state.CurrentState {
+		case StateSelectVisorHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for vegavisor home", state.Settings.VisorHome)
+			} else {
+				visorHome, err := uilib.AskPath(ui, "vegavisor home", state.Settings.VisorHome)
+				if err != nil {
+					return fmt.Errorf("failed getting vegavisor home: %w", err)
+				}
+				state.Settings.VisorHome = visorHome
+			}
+
+			if utils.FileExists(state.Settings.VisorHome) {
+				state.CurrentState = StateExistingVisorHome
+			} else {
+				state.CurrentState = StateSelectVegaHome
+			}
+
+		case StateExistingVisorHome:
+			if err := state.selectHomeAction(ui, "vegavisor home", state.Settings.VisorHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateSelectVegaHome
+
+		case StateSelectVegaHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for vega home", state.Settings.VegaHome)
+			} else {
+				vegaHome, err := uilib.AskPath(ui, "vega home", state.Settings.VegaHome)
+				if err != nil {
+					return fmt.Errorf("failed getting vega home: %w", err)
+				}
+				state.Settings.VegaHome = vegaHome
+			}
+
+			if utils.FileExists(state.Settings.VegaHome) {
+				state.CurrentState = StateExistingVegaHome
+			} else {
+				state.CurrentState = StateSelectTendermintHome
+			}
+
+		case StateExistingVegaHome:
+			if err := state.selectHomeAction(ui, "vega home", state.Settings.VegaHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateSelectTendermintHome
+
+		case StateSelectTendermintHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for tendermint home", state.Settings.TendermintHome)
+			} else {
+				tendermintHome, err := uilib.AskPath(ui, "tendermint home", state.Settings.TendermintHome)
+				if err != nil {
+					return fmt.Errorf("failed getting tendermint home: %w", err)
+				}
+				state.Settings.TendermintHome = tendermintHome
+			}
+
+			if utils.FileExists(state.Settings.TendermintHome) {
+				state.CurrentState = StateExistingTendermintHome
+			} else {
+				state.CurrentState = StateGetExternalHost
+			}
+
+		case StateExistingTendermintHome:
+			if err := state.selectHomeAction(ui, "tendermint home", state.Settings.TendermintHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateGetExternalHost
+
+		case StateGetExternalHost:
+			if state.Settings.NonInteractive {
+				if err := validateExternalHost(state.Settings.ExternalHost); err != nil {
+					return fmt.Errorf("invalid external host: %w", err)
+				}
+				state.logger.Infof("NonInteractive: Using %s as the external host", state.Settings.ExternalHost)
+			} else {
+				host, err := uilib.AskString(
+					ui,
+					"Public IP or domain name other nodes use to reach your seed node",
+					state.Settings.ExternalHost,
+					validateExternalHost,
+				)
+				if err != nil {
+					return fmt.Errorf("failed getting external host: %w", err)
+				}
+				state.Settings.ExternalHost = host
+			}
+			state.CurrentState = StateGetP2PPort
+
+		case StateGetP2PPort:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %d as the tendermint p2p port", state.Settings.P2PPort)
+			} else {
+				port, err := uilib.AskInt(ui, "Tendermint p2p port", state.Settings.P2PPort)
+				if err != nil {
+					return fmt.Errorf("failed getting tendermint p2p port: %w", err)
+				}
+				state.Settings.P2PPort = port
+			}
+
+			if state.Settings.P2PPort < 1 || state.Settings.P2PPort > 65535 {
+				return fmt.Errorf("invalid tendermint p2p port %d", state.Settings.P2PPort)
+			}
+			state.CurrentState = StateGetPeerLimits
+
+		case StateGetPeerLimits:
+			if state.Settings.NonInteractive {
+				state.logger.Infof(
+					"NonInteractive: Using peer limits: inbound(%d), outbound(%d)",
+					state.Settings.MaxInboundPeers,
+					state.Settings.MaxOutboundPeers,
+				)
+			} else {
+				inboundPeers, err := uilib.AskInt(ui, "Maximum number of inbound peers", state.Settings.MaxInboundPeers)
+				if err != nil {
+					return fmt.Errorf("failed getting maximum number of inbound peers: %w", err)
+				}
+				outboundPeers, err := uilib.AskInt(ui, "Maximum number of outbound peers", state.Settings.MaxOutboundPeers)
+				if err != nil {
+					return fmt.Errorf("failed getting maximum number of outbound peers: %w", err)
+				}
+				state.Settings.MaxInboundPeers = inboundPeers
+				state.Settings.MaxOutboundPeers = outboundPeers
+			}
+
+			if state.Settings.MaxInboundPeers < 1 || state.Settings.MaxOutboundPeers < 1 {
+				return fmt.Errorf("peer limits must be greater than 0")
+			}
+			state.CurrentState = StateCheckLatestVersion
+
+		case StateCheckLatestVersion:
+			statisticsResponse, err := apiClient.Statistics(context.Background())
+			if err != nil {
+				return fmt.Errorf("failed to get response for the /statistics endpoint from the %s servers: %w", networkConfig.Name, err)
+			}
+
+			// Seed node does not replay the chain, so it always starts with the latest binary
+			vegaVersion, visorVersion, err := node.Versions(statisticsResponse, networkConfig, state.Settings.RequestedVegaVersion, false)
+			if err != nil {
+				return fmt.Errorf("failed to get binaries versions: %w", err)
+			}
+
+			state.Settings.VegaBinaryVersion = vegaVersion
+			state.Settings.VisorBinaryVersion = visorVersion
+			state.Settings.VegaChainId = statisticsResponse.ChainID
+			state.CurrentState = StateSummary
+
+		case StateSummary:
+			printSummary(state.Settings, networkConfig.Name)
+
+			if state.Settings.NonInteractive {
+				state.logger.Info("NonInteractive: Moving to installation steps")
+
+				break STATE_RUN
+			}
+
+			correctResponse, err := uilib.AskYesNo(ui, "Is it correct?", uilib.AnswerYes)
+			if err != nil {
+				return fmt.Errorf("failed asking for correct summary: %w", err)
+			}
+
+			if correctResponse == uilib.AnswerNo {
+				state.CurrentState = StateSelectVisorHome
+				break
+			}
+
+			break STATE_RUN
+		}
+	}
+
+	return nil
+}
+
+// selectHomeAction asks what to do with the existing home. The selected action is executed by the generator.
+func (state *StateMachine) selectHomeAction(ui *input.UI, name, homePath string) error {
+	actions, err := node.SelectHomeAction(
+		state.logger,
+		ui,
+		state.Settings.NonInteractive,
+		state.Settings.RemoveExistingFiles,
+		state.Settings.HomeActions,
+		state.Settings.Backup.Action,
+		name,
+		homePath,
+	)
+	if err != nil {
+		return err
+	}
+	state.Settings.HomeActions = actions
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package seednode
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+func validateExternalHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("external host cannot be empty")
+	}
+
+	if strings.ContainsAny(host, "/:@ ") && net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid external host '%s': expected IP address or domain name without port", host)
+	}
+
+	return nil
+}
+
+func printSummary(settings GenerateSettings, networkName string) {
+	fmt.Print("\n Summary:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Parameter", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("Network", networkName)
+	tbl.AddRow("Visor Home", settings.VisorHome)
+	tbl.AddRow("Vega Home", settings.VegaHome)
+	tbl.AddRow("Tendermint Home", settings.TendermintHome)
+	tbl.AddRow("Preserve Identity", settings.PreserveIdentity)
+	tbl.AddRow("External Host", settings.ExternalHost)
+	tbl.AddRow("P2P Port", settings.P2PPort)
+	tbl.AddRow("Max Inbound Peers", settings.MaxInboundPeers)
+	tbl.AddRow("Max Outbound Peers", settings.MaxOutboundPeers)
+	tbl.AddRow("Vega Version", settings.VegaBinaryVersion)
+	tbl.AddRow("Vega Chain ID", settings.VegaChainId)
+
+	tbl.Print()
+	fmt.Println("")
+}
+
+func PrintInstructions(visorHome, seedAddress string) {
+	fmt.Printf(`
+    The seed node is initialized. Its address is:
+
+      %s
+
+    Add it to the tendermint-seeds of your network definition, so other nodes can discover peers through it.
+    Make sure the p2p port is reachable from the internet. You can now start it with the following command:
+
+      %s/visor run --home %s
+
+    You can also setup systemd service if you running your node on LINUX with the following command:
+
+      sudo vega-assistant setup systemd --visor-home %s
+`, seedAddress, visorHome, visorHome, visorHome)
+}
@@ -0,0 +1,180 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+const requestTimeout = 5 * time.Second
+
+// LocalEndpoints are the API endpoints of the node running on this machine. Empty endpoint is not checked.
+type LocalEndpoints struct {
+	CoreREST      string
+	DataNodeREST  string
+	TendermintRPC string
+}
+
+type NodeStatus struct {
+	Network           string    `json:"network"`
+	ChainID           string    `json:"chain-id"`
+	NetworkHeight     uint64    `json:"network-height"`
+	NetworkVegaTime   time.Time `json:"network-vega-time"`
+	NetworkAppVersion string    `json:"network-app-version"`
+
+	CoreHeight     uint64    `json:"core-height"`
+	CoreVegaTime   time.Time `json:"core-vega-time"`
+	CoreAppVersion string    `json:"core-app-version"`
+	CoreChainID    string    `json:"core-chain-id"`
+
+	DataNodeHeight uint64 `json:"data-node-height,omitempty"`
+	// DataNodeLag is the number of blocks the data-node is behind the local core
+	DataNodeLag int64 `json:"data-node-lag"`
+
+	TendermintHeight     uint64 `json:"tendermint-height"`
+	TendermintCatchingUp bool   `json:"tendermint-catching-up"`
+
+	// BlocksLag is the number of blocks the local core is behind the network
+	BlocksLag int64  `json:"blocks-lag"`
+	TimeLag   string `json:"time-lag"`
+	// CatchUpSpeed is the number of blocks per second the node gains on the network
+	CatchUpSpeed     float64 `json:"catch-up-blocks-per-second"`
+	EstimatedCatchUp string  `json:"estimated-catch-up"`
+
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Collect compares the local node with the network. The local heights are measured twice in the
+// sample period to estimate how fast the node is catching up.
+func Collect(
+	apiClient *vegaapi.NetworkAPI,
+	networkName string,
+	endpoints LocalEndpoints,
+	samplePeriod time.Duration,
+) (*NodeStatus, error) {
+	result := &NodeStatus{Network: networkName}
+
+	firstNetworkStats, firstCoreStats, err := collectSample(apiClient, endpoints, result)
+	if err != nil {
+		return nil, err
+	}
+	if firstCoreStats == nil || samplePeriod <= 0 {
+		result.fill(firstNetworkStats, firstCoreStats)
+		return result, nil
+	}
+
+	time.Sleep(samplePeriod)
+
+	// Errors are collected again in the second sample
+	result.Errors = nil
+	networkStats, coreStats, err := collectSample(apiClient, endpoints, result)
+	if err != nil {
+		return nil, err
+	}
+	result.fill(networkStats, coreStats)
+
+	if coreStats != nil {
+		elapsed := networkStats.CurrentTime.Sub(firstNetworkStats.CurrentTime).Seconds()
+		if elapsed <= 0 {
+			elapsed = samplePeriod.Seconds()
+		}
+
+		coreSpeed := float64(int64(coreStats.BlockHeight)-int64(firstCoreStats.BlockHeight)) / elapsed
+		networkSpeed := float64(int64(networkStats.BlockHeight)-int64(firstNetworkStats.BlockHeight)) / elapsed
+		result.CatchUpSpeed = coreSpeed - networkSpeed
+		result.EstimatedCatchUp = estimateCatchUp(result.BlocksLag, result.CatchUpSpeed)
+	}
+
+	return result, nil
+}
+
+func collectSample(
+	apiClient *vegaapi.NetworkAPI,
+	endpoints LocalEndpoints,
+	result *NodeStatus,
+) (*types.VegaStatistics, *types.VegaStatistics, error) {
+	ctx, cancel := requestContext()
+	networkStats, err := apiClient.LatestStatistics(ctx)
+	cancel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get network statistics: %w", err)
+	}
+
+	var coreStats *types.VegaStatistics
+	if endpoints.CoreREST != "" {
+		ctx, cancel := requestContext()
+		coreStats, err = apiClient.NodeStatistics(ctx, endpoints.CoreREST)
+		cancel()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("core: %s", err.Error()))
+		}
+	}
+
+	if endpoints.DataNodeREST != "" {
+		ctx, cancel := requestContext()
+		dataNodeStats, err := apiClient.NodeStatistics(ctx, endpoints.DataNodeREST)
+		cancel()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("data-node: %s", err.Error()))
+		} else {
+			result.DataNodeHeight = dataNodeStats.DataNodeHeight
+		}
+	}
+
+	if endpoints.TendermintRPC != "" {
+		ctx, cancel := requestContext()
+		syncInfo, err := apiClient.TendermintSyncInfo(ctx, endpoints.TendermintRPC)
+		cancel()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("tendermint: %s", err.Error()))
+		} else {
+			result.TendermintHeight = syncInfo.BlockHeight
+			result.TendermintCatchingUp = syncInfo.CatchingUp
+		}
+	}
+
+	return networkStats, coreStats, nil
+}
+
+// requestContext returns the context for the single request. Every request has its own timeout,
+// so the slow network lookup does not use up the time of the local checks.
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
+
+func (s *NodeStatus) fill(networkStats, coreStats *types.VegaStatistics) {
+	s.ChainID = networkStats.ChainID
+	s.NetworkHeight = networkStats.BlockHeight
+	s.NetworkVegaTime = networkStats.VegaTime
+	s.NetworkAppVersion = networkStats.AppVersion
+
+	if coreStats == nil {
+		return
+	}
+
+	s.CoreHeight = coreStats.BlockHeight
+	s.CoreVegaTime = coreStats.VegaTime
+	s.CoreAppVersion = coreStats.AppVersion
+	s.CoreChainID = coreStats.ChainID
+	s.BlocksLag = int64(networkStats.BlockHeight) - int64(coreStats.BlockHeight)
+	s.TimeLag = networkStats.VegaTime.Sub(coreStats.VegaTime).Round(time.Second).String()
+
+	if s.DataNodeHeight > 0 {
+		s.DataNodeLag = int64(coreStats.BlockHeight) - int64(s.DataNodeHeight)
+	}
+}
+
+func estimateCatchUp(blocksLag int64, catchUpSpeed float64) string {
+	if blocksLag <= 0 {
+		return "synced"
+	}
+
+	if catchUpSpeed <= 0 {
+		return "not catching up"
+	}
+
+	return (time.Duration(float64(blocksLag)/catchUpSpeed) * time.Second).Round(time.Second).String()
+}
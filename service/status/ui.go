@@ -0,0 +1,57 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+func PrintStatus(status *NodeStatus, output string) error {
+	if output == OutputJSON {
+		result, err := json.MarshalIndent(status, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Println(string(result))
+
+		return nil
+	}
+
+	fmt.Print("\n Status:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Parameter", "Network", "Local node")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("Network", status.Network, "")
+	tbl.AddRow("Chain ID", status.ChainID, status.CoreChainID)
+	tbl.AddRow("App Version", status.NetworkAppVersion, status.CoreAppVersion)
+	tbl.AddRow("Core Height", status.NetworkHeight, status.CoreHeight)
+	tbl.AddRow("Vega Time", status.NetworkVegaTime, status.CoreVegaTime)
+	tbl.AddRow("Tendermint Height", "", status.TendermintHeight)
+	tbl.AddRow("Tendermint Catching Up", "", status.TendermintCatchingUp)
+	if status.DataNodeHeight > 0 {
+		tbl.AddRow("Data-node Height", "", status.DataNodeHeight)
+		tbl.AddRow("Data-node Lag (blocks behind core)", "", status.DataNodeLag)
+	}
+	tbl.AddRow("Blocks Lag", "", status.BlocksLag)
+	tbl.AddRow("Time Lag", "", status.TimeLag)
+	tbl.AddRow("Catch-up Speed (blocks/s)", "", fmt.Sprintf("%.2f", status.CatchUpSpeed))
+	tbl.AddRow("Estimated Catch-up", "", status.EstimatedCatchUp)
+
+	tbl.Print()
+	fmt.Println("")
+
+	for _, statusErr := range status.Errors {
+		color.Red("Error: %s", statusErr)
+	}
+
+	return nil
+}
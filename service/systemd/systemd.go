@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"path/filepath"
 	"runtime"
 
 	"go.uber.org/zap"
@@ -32,7 +33,29 @@ AmbientCapabilities=CAP_NET_BIND_SERVICE
 [Install]
 WantedBy=multi-user.target`
 
-const serviceFilePath = "/lib/systemd/system/vegavisor.service"
+const (
+	serviceName     = "vegavisor"
+	serviceFilePath = "/lib/systemd/system/vegavisor.service"
+)
+
+type Settings struct {
+	VisorHome string `toml:"visor-home"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		VisorHome: filepath.Join(utils.CurrentUserHomePath(), "vegavisor_home"),
+	}
+}
+
+// Validate checks the settings loaded from the config file and flags
+func (settings Settings) Validate() error {
+	if settings.VisorHome == "" {
+		return fmt.Errorf("visor-home cannot be empty")
+	}
+
+	return nil
+}
 
 func PrepareSystemd(logger *zap.SugaredLogger, visorHome string) error {
 	if runtime.GOOS != "linux" {
@@ -90,3 +113,18 @@ func templateSystemdService(visorHome, username, groupname string) (string, erro
 
 	return buff.String(), nil
 }
+
+// RestartService restarts the vegavisor systemd service to apply the new node configuration
+func RestartService(logger *zap.SugaredLogger) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("systemd supported only on Linux")
+	}
+
+	logger.Infof("Calling systemctl restart %s", serviceName)
+	if _, err := utils.ExecuteBinary("systemctl", []string{"restart", serviceName}, nil); err != nil {
+		return fmt.Errorf("failed to restart the %s service: %w", serviceName, err)
+	}
+	logger.Info("Service restarted")
+
+	return nil
+}
@@ -0,0 +1,394 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/node"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+type ValidatorGenerator struct {
+	vegaApi         *vegaapi.NetworkAPI
+	userSettings    GenerateSettings
+	networkConfig   network.NetworkConfig
+	downloadOptions github.DownloadOptions
+
+	identity         *node.Identity
+	retainedIdentity []string
+}
+
+func NewValidatorGenerator(
+	vegaApi *vegaapi.NetworkAPI,
+	settings GenerateSettings,
+	networkConfig network.NetworkConfig,
+	downloadOptions github.DownloadOptions,
+) (*ValidatorGenerator, error) {
+	return &ValidatorGenerator{
+		vegaApi:         vegaApi,
+		userSettings:    settings,
+		networkConfig:   networkConfig,
+		downloadOptions: downloadOptions,
+	}, nil
+}
+
+// Run prepares the validator node and returns the description of its node wallets. All the lookups and downloads
+// are done before the homes are touched. When any step fails, the homes are rolled back to the state from before the setup.
+func (gen *ValidatorGenerator) Run(logger *zap.SugaredLogger) (string, error) {
+	outputDir, err := os.MkdirTemp("", "vega-assistant")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	vegaBinaryPath, visorBinaryPath, err := node.DownloadBinaries(
+		logger,
+		gen.networkConfig,
+		gen.downloadOptions,
+		gen.userSettings.VegaBinaryVersion,
+		gen.userSettings.VisorBinaryVersion,
+		outputDir,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to download binaries: %w", err)
+	}
+
+	genesisPath := filepath.Join(outputDir, "genesis.json")
+	if err := utils.DownloadFile(gen.networkConfig.GenesisURL, genesisPath); err != nil {
+		return "", fmt.Errorf("failed to download genesis: %w", err)
+	}
+
+	var restartSnapshot *types.CoreSnapshot
+	if gen.userSettings.Mode == StartFromSnapshot {
+		restartSnapshot, err = node.SelectStateSyncSnapshot(context.Background(), logger, gen.vegaApi)
+		if err != nil {
+			return "", fmt.Errorf("failed to select snapshot for restart: %w", err)
+		}
+	}
+
+	configFiles, err := gen.configFiles(logger, restartSnapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare config files for the node: %w", err)
+	}
+
+	if gen.userSettings.PreserveIdentity {
+		gen.identity, err = node.ReadIdentity(gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := gen.verifyNodeWalletPassphrase(vegaBinaryPath, outputDir); err != nil {
+		return "", err
+	}
+
+	transaction, err := node.BeginHomesTransaction(logger, gen.homes()...)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare homes: %w", err)
+	}
+
+	nodeWallets, err := gen.install(logger, vegaBinaryPath, visorBinaryPath, genesisPath, configFiles)
+	if err != nil {
+		logger.Infof("Setup failed: %s", err.Error())
+		if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+			return "", fmt.Errorf("%w: rollback failed, clean the homes manually: %s", err, rollbackErr.Error())
+		}
+
+		return "", err
+	}
+
+	if err := transaction.Commit(gen.userSettings.HomeActions, gen.userSettings.Backup); err != nil {
+		return "", fmt.Errorf("node is ready but failed to remove previous homes: %w", err)
+	}
+
+	return nodeWallets, nil
+}
+
+// install initializes the node in the homes and puts binaries, configs and genesis in place
+func (gen *ValidatorGenerator) install(
+	logger *zap.SugaredLogger,
+	vegaBinaryPath, visorBinaryPath, genesisPath string,
+	configFiles []node.ConfigFile,
+) (string, error) {
+	if err := gen.writeNodeWalletPassphrase(logger); err != nil {
+		return "", fmt.Errorf("failed to write node wallet passphrase: %w", err)
+	}
+
+	if err := gen.initNode(logger, visorBinaryPath, vegaBinaryPath); err != nil {
+		return "", fmt.Errorf("failed to init vega validator node: %w", err)
+	}
+
+	retained, err := gen.identity.Restore(logger, gen.userSettings.VegaHome, gen.userSettings.TendermintHome)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore node identity: %w", err)
+	}
+	gen.retainedIdentity = retained
+
+	if gen.identity.HasNodeWallets() {
+		// The retained wallets registry already contains the vega, ethereum and tendermint node wallets
+		logger.Info("Using node wallets retained from the previous vega home")
+	} else if err := gen.setupNodeWallets(logger, vegaBinaryPath); err != nil {
+		return "", fmt.Errorf("failed to setup node wallets: %w", err)
+	}
+
+	runConfig := vegacmd.VisorRunConfig{
+		Version:                  gen.visorVersionName(),
+		VegaHome:                 gen.userSettings.VegaHome,
+		TendermintHome:           gen.userSettings.TendermintHome,
+		NodeWalletPassphraseFile: gen.userSettings.NodeWalletPassphraseFile,
+	}
+	if err := node.PrepareVisorHome(logger, gen.userSettings.VisorHome, runConfig); err != nil {
+		return "", fmt.Errorf("failed to prepare visor home: %w", err)
+	}
+
+	if err := node.CopyBinaries(logger, gen.userSettings.VisorHome, gen.visorVersionName(), vegaBinaryPath, visorBinaryPath); err != nil {
+		return "", fmt.Errorf("failed to copy binaries to visor home: %w", err)
+	}
+
+	for _, configFile := range configFiles {
+		if err := node.UpdateConfigFile(logger, configFile.Name, configFile.Path, configFile.Values); err != nil {
+			return "", fmt.Errorf("failed to update config files for the node: %w", err)
+		}
+	}
+
+	genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
+	logger.Infof("Copying genesis from %s to %s", genesisPath, genesisDestination)
+	if err := utils.CopyFile(genesisPath, genesisDestination); err != nil {
+		return "", fmt.Errorf("failed to copy genesis: %w", err)
+	}
+	logger.Info("Genesis copied")
+
+	nodeWallets, err := vegacmd.ShowNodeWallets(vegaBinaryPath, gen.userSettings.VegaHome, gen.userSettings.NodeWalletPassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe node wallets: %w", err)
+	}
+
+	return nodeWallets, nil
+}
+
+// RetainedIdentity returns the paths of the identity files retained from the previous homes
+func (gen *ValidatorGenerator) RetainedIdentity() []string {
+	return gen.retainedIdentity
+}
+
+// homes returns all the homes of the node
+func (gen *ValidatorGenerator) homes() []string {
+	return []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+	}
+}
+
+// visorVersionName returns the name of the visor directory for the vega binary
+func (gen *ValidatorGenerator) visorVersionName() string {
+	if gen.userSettings.Mode == StartFromBlock0 {
+		return node.GenesisVersionName
+	}
+
+	return gen.userSettings.VegaBinaryVersion
+}
+
+// verifyNodeWalletPassphrase checks the passphrase decrypts the retained node wallets before the homes are replaced,
+// otherwise the node would be started with the passphrase which cannot decrypt its wallets
+func (gen *ValidatorGenerator) verifyNodeWalletPassphrase(vegaBinaryPath, outputDir string) error {
+	if !gen.identity.HasNodeWallets() {
+		return nil
+	}
+
+	passphraseFile := gen.userSettings.NodeWalletPassphraseFile
+	if gen.userSettings.NodeWalletPassphrase != "" {
+		// The passphrase file is written in the node setup, when the passphrase is verified
+		passphraseFile = filepath.Join(outputDir, "nodewallet-passphrase.txt")
+		if err := os.WriteFile(passphraseFile, []byte(gen.userSettings.NodeWalletPassphrase), 0o600); err != nil {
+			return fmt.Errorf("failed to write temporary passphrase file: %w", err)
+		}
+	}
+
+	if err := gen.identity.VerifyNodeWalletsPassphrase(vegaBinaryPath, passphraseFile, filepath.Join(outputDir, "nodewallets")); err != nil {
+		return fmt.Errorf("failed to verify the node wallet passphrase, provide the passphrase of the existing node wallets: %w", err)
+	}
+
+	return nil
+}
+
+func (gen *ValidatorGenerator) writeNodeWalletPassphrase(logger *zap.SugaredLogger) error {
+	if gen.userSettings.NodeWalletPassphrase == "" {
+		logger.Infof("Using existing node wallet passphrase file %s", gen.userSettings.NodeWalletPassphraseFile)
+		return nil
+	}
+
+	passphraseFile := gen.userSettings.NodeWalletPassphraseFile
+	logger.Infof("Writing node wallet passphrase to %s", passphraseFile)
+	if err := os.MkdirAll(filepath.Dir(passphraseFile), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory for the passphrase file: %w", err)
+	}
+
+	if err := os.WriteFile(passphraseFile, []byte(gen.userSettings.NodeWalletPassphrase), 0o600); err != nil {
+		return fmt.Errorf("failed to write passphrase file %s: %w", passphraseFile, err)
+	}
+	logger.Info("Node wallet passphrase file saved")
+
+	return nil
+}
+
+func (gen *ValidatorGenerator) initNode(
+	logger *zap.SugaredLogger,
+	visorBinary, vegaBinary string,
+) error {
+	logger.Infof("Initializing vegavisor in the %s", gen.userSettings.VisorHome)
+	if err := vegacmd.InitVisor(visorBinary, gen.userSettings.VisorHome); err != nil {
+		return fmt.Errorf(
+			"failed to initialize vegavisor in %s: %w",
+			gen.userSettings.VisorHome,
+			err,
+		)
+	}
+	logger.Info("Visor successfully initialized")
+
+	logger.Infof("Initializing tendermint in the %s", gen.userSettings.TendermintHome)
+	if err := vegacmd.InitTendermint(vegaBinary, gen.userSettings.TendermintHome); err != nil {
+		return fmt.Errorf(
+			"failed to initialize tendermint in %s: %w",
+			gen.userSettings.TendermintHome,
+			err,
+		)
+	}
+	logger.Info("Tendermint successfully initialized")
+
+	logger.Infof("Initializing vega validator in the %s", gen.userSettings.VegaHome)
+	if err := vegacmd.InitValidator(vegaBinary, gen.userSettings.VegaHome, gen.userSettings.NodeWalletPassphraseFile); err != nil {
+		return fmt.Errorf(
+			"failed to initialize vega validator in %s: %w",
+			gen.userSettings.VegaHome,
+			err,
+		)
+	}
+	logger.Info("Vega validator successfully initialized")
+
+	return nil
+}
+
+func (gen *ValidatorGenerator) setupNodeWallets(logger *zap.SugaredLogger, vegaBinary string) error {
+	wallets := []struct {
+		chain    vegacmd.NodeWalletChain
+		settings WalletSettings
+	}{
+		{chain: vegacmd.NodeWalletChainVega, settings: gen.userSettings.VegaWallet},
+		{chain: vegacmd.NodeWalletChainEthereum, settings: gen.userSettings.EthereumWallet},
+	}
+
+	for _, wallet := range wallets {
+		if wallet.settings.Source == WalletImport {
+			logger.Infof("Importing %s node wallet from %s", wallet.chain, wallet.settings.Path)
+			if err := vegacmd.ImportNodeWallet(
+				vegaBinary,
+				gen.userSettings.VegaHome,
+				gen.userSettings.NodeWalletPassphraseFile,
+				wallet.chain,
+				wallet.settings.Path,
+				wallet.settings.PassphraseFile,
+			); err != nil {
+				return err
+			}
+			logger.Infof("The %s node wallet imported", wallet.chain)
+			continue
+		}
+
+		logger.Infof("Generating %s node wallet", wallet.chain)
+		if err := vegacmd.GenerateNodeWallet(
+			vegaBinary,
+			gen.userSettings.VegaHome,
+			gen.userSettings.NodeWalletPassphraseFile,
+			wallet.chain,
+		); err != nil {
+			return err
+		}
+		logger.Infof("The %s node wallet generated", wallet.chain)
+	}
+
+	logger.Infof("Importing tendermint node wallet from %s", gen.userSettings.TendermintHome)
+	if err := vegacmd.ImportTendermintNodeWallet(
+		vegaBinary,
+		gen.userSettings.VegaHome,
+		gen.userSettings.NodeWalletPassphraseFile,
+		gen.userSettings.TendermintHome,
+	); err != nil {
+		return err
+	}
+	logger.Info("The tendermint node wallet imported")
+
+	return nil
+}
+
+// configFiles returns new values for the core, tendermint and vegavisor config files
+func (gen *ValidatorGenerator) configFiles(
+	logger *zap.SugaredLogger,
+	restartSnapshot *types.CoreSnapshot,
+) ([]node.ConfigFile, error) {
+	healthyTendermintRPCServers, err := node.HealthyRPCServers(context.Background(), gen.vegaApi, gen.networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tendermint rpc servers: %w", err)
+	}
+
+	tendermintSeeds, err := node.TendermintSeeds(context.Background(), logger, gen.vegaApi, gen.networkConfig, healthyTendermintRPCServers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tendermint seeds: %w", err)
+	}
+
+	vegaConfig := map[string]interface{}{
+		"Snapshot.StartHeight":  -1,
+		"Ethereum.RPCEndpoint":  gen.userSettings.EthereumRPCEndpoint,
+		"Broker.Socket.Enabled": false,
+	}
+
+	if len(gen.userSettings.EVMChains) > 0 {
+		evmBridgeConfigs := []map[string]interface{}{}
+		for _, chain := range gen.userSettings.EVMChains {
+			evmBridgeConfigs = append(evmBridgeConfigs, map[string]interface{}{
+				"ChainID":     chain.ChainID,
+				"RPCEndpoint": chain.RPCEndpoint,
+			})
+		}
+		vegaConfig["Ethereum.EVMBridgeConfigs"] = evmBridgeConfigs
+	}
+
+	tendermintConfig := node.TendermintConfig(
+		tendermintSeeds,
+		gen.networkConfig.TendermintPersistentPeers,
+		healthyTendermintRPCServers,
+	)
+
+	if gen.userSettings.Mode == StartFromSnapshot {
+		if err := node.EnableStateSync(tendermintConfig, restartSnapshot); err != nil {
+			return nil, fmt.Errorf("failed to start node from snapshot: %w", err)
+		}
+	}
+
+	return []node.ConfigFile{
+		{
+			Name:   "vega-core",
+			Path:   filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath),
+			Values: vegaConfig,
+		},
+		{
+			Name:   "tendermint",
+			Path:   filepath.Join(gen.userSettings.TendermintHome, vegacmd.TenderminConfigPath),
+			Values: tendermintConfig,
+		},
+		{
+			Name:   "vegavisor",
+			Path:   filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath),
+			Values: node.VisorConfig(gen.networkConfig.Repository),
+		},
+	}, nil
+}
@@ -0,0 +1,429 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/service/backup"
+	"github.com/daniel1302/vega-assistant/service/node"
+	"github.com/daniel1302/vega-assistant/uilib"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type (
+	State        int
+	StartupMode  string
+	WalletSource string
+)
+
+const (
+	StartFromBlock0   StartupMode = "start-from-block-0"
+	StartFromSnapshot StartupMode = "start-from-snapshot"
+)
+
+const (
+	WalletGenerate WalletSource = "generate"
+	WalletImport   WalletSource = "import"
+)
+
+const (
+	StateSelectStartupMode State = iota
+	StateSelectVisorHome
+	StateExistingVisorHome
+	StateSelectVegaHome
+	StateExistingVegaHome
+	StateSelectTendermintHome
+	StateExistingTendermintHome
+	StateGetNodeWalletPassphrase
+	StateSelectVegaWallet
+	StateSelectEthereumWallet
+	StateGetEthereumRPCEndpoint
+	StateGetEVMChains
+	StateCheckLatestVersion
+	StateSummary
+)
+
+type StateMachine struct {
+	CurrentState State
+	Settings     GenerateSettings
+
+	logger *zap.SugaredLogger
+}
+
+type WalletSettings struct {
+	Source         WalletSource `toml:"source"`
+	Path           string       `toml:"path"`
+	PassphraseFile string       `toml:"passphrase-file"`
+}
+
+type EVMChain struct {
+	ChainID     string `toml:"chain-id"`
+	RPCEndpoint string `toml:"rpc-endpoint"`
+}
+
+type GenerateSettings struct {
+	Mode StartupMode `toml:"mode"`
+
+	NonInteractive           bool           `toml:"non-interactive"`
+	VisorHome                string         `toml:"visor-home"`
+	VegaHome                 string         `toml:"vega-home"`
+	TendermintHome           string         `toml:"tendermint-home"`
+	NodeWalletPassphraseFile string         `toml:"nodewallet-passphrase-file"`
+	NodeWalletPassphrase     string         `toml:"-"`
+	VegaWallet               WalletSettings `toml:"vega-wallet"`
+	EthereumWallet           WalletSettings `toml:"ethereum-wallet"`
+	EthereumRPCEndpoint      string         `toml:"ethereum-rpc-endpoint"`
+	EVMChains                []EVMChain     `toml:"evm-chains"`
+	VisorBinaryVersion       string
+	VegaBinaryVersion        string
+	VegaChainId              string
+	RemoveExistingFiles      bool            `toml:"remove-existing-file"`
+	PreserveIdentity         bool            `toml:"preserve-identity"`
+	Backup                   backup.Settings `toml:"backup"`
+	// HomeActions are actions for the existing homes selected by the user. The Backup.Action is used for other homes.
+	HomeActions node.HomeActions `toml:"-"`
+	// RetainNodeWallets is true when the node wallets from the existing vega home are preserved
+	RetainNodeWallets bool `toml:"-"`
+	// RequestedVegaVersion is the release selected with the --vega-version flag instead of the network version
+	RequestedVegaVersion string `toml:"-"`
+}
+
+func DefaultGenerateSettings() *GenerateSettings {
+	return &GenerateSettings{
+		NonInteractive:           false,
+		Mode:                     StartFromSnapshot,
+		VisorHome:                filepath.Join(utils.CurrentUserHomePath(), "vegavisor_home"),
+		VegaHome:                 filepath.Join(utils.CurrentUserHomePath(), "vega_home"),
+		TendermintHome:           filepath.Join(utils.CurrentUserHomePath(), "tendermint_home"),
+		NodeWalletPassphraseFile: filepath.Join(utils.CurrentUserHomePath(), "vega_nodewallet_passphrase.txt"),
+		VegaWallet:               WalletSettings{Source: WalletGenerate},
+		EthereumWallet:           WalletSettings{Source: WalletGenerate},
+		RemoveExistingFiles:      false,
+		Backup:                   backup.DefaultSettings(),
+		HomeActions:              node.HomeActions{},
+	}
+}
+
+// Validate checks the settings loaded from the config file and flags
+func (settings GenerateSettings) Validate() error {
+	if settings.Mode != StartFromBlock0 && settings.Mode != StartFromSnapshot {
+		return fmt.Errorf("invalid startup mode %q: must be %s or %s", settings.Mode, StartFromBlock0, StartFromSnapshot)
+	}
+
+	if err := utils.ValidateNotEmpty(map[string]string{
+		"visor-home":      settings.VisorHome,
+		"vega-home":       settings.VegaHome,
+		"tendermint-home": settings.TendermintHome,
+	}); err != nil {
+		return err
+	}
+
+	if err := settings.Backup.Validate(); err != nil {
+		return err
+	}
+
+	for name, wallet := range map[string]WalletSettings{
+		"vega-wallet":     settings.VegaWallet,
+		"ethereum-wallet": settings.EthereumWallet,
+	} {
+		if wallet.Source != WalletGenerate && wallet.Source != WalletImport {
+			return fmt.Errorf("invalid %s.source %q: expected %s or %s", name, wallet.Source, WalletGenerate, WalletImport)
+		}
+	}
+
+	if settings.EthereumRPCEndpoint != "" {
+		if err := validateRPCEndpoint(settings.EthereumRPCEndpoint); err != nil {
+			return err
+		}
+	}
+
+	for _, chain := range settings.EVMChains {
+		if chain.ChainID == "" {
+			return fmt.Errorf("evm-chains.chain-id cannot be empty")
+		}
+		if err := validateRPCEndpoint(chain.RPCEndpoint); err != nil {
+			return fmt.Errorf("invalid rpc endpoint for the %s evm chain: %w", chain.ChainID, err)
+		}
+	}
+
+	return nil
+}
+
+func NewStateMachine(logger *zap.SugaredLogger, config GenerateSettings) StateMachine {
+	return StateMachine{
+		logger:       logger,
+		CurrentState: StateSelectStartupMode,
+		Settings:     config,
+	}
+}
+
+func (state StateMachine) Dump() string {
+	result, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return ""
+	}
+
+	return string(result)
+}
+
+func (state *StateMachine) Run(apiClient *vegaapi.NetworkAPI, ui *input.UI, networkConfig network.NetworkConfig) error {
+STATE_RUN:
+	for {
+		switch state.CurrentState {
+		case StateSelectStartupMode:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s mode", state.Settings.Mode)
+			} else {
+				mode, err := SelectStartupMode(ui, state.Settings.Mode)
+				if err != nil {
+					return fmt.Errorf("failed selecting startup mode: %w", err)
+				}
+				state.Settings.Mode = *mode
+			}
+
+			state.CurrentState = StateSelectVisorHome
+
+		case StateSelectVisorHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for vegavisor home", state.Settings.VisorHome)
+			} else {
+				visorHome, err := uilib.AskPath(ui, "vegavisor home", state.Settings.VisorHome)
+				if err != nil {
+					return fmt.Errorf("failed getting vegavisor home: %w", err)
+				}
+				state.Settings.VisorHome = visorHome
+			}
+
+			if utils.FileExists(state.Settings.VisorHome) {
+				state.CurrentState = StateExistingVisorHome
+			} else {
+				state.CurrentState = StateSelectVegaHome
+			}
+
+		case StateExistingVisorHome:
+			if err := state.selectHomeAction(ui, "vegavisor home", state.Settings.VisorHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateSelectVegaHome
+
+		case StateSelectVegaHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for vega home", state.Settings.VegaHome)
+			} else {
+				vegaHome, err := uilib.AskPath(ui, "vega home", state.Settings.VegaHome)
+				if err != nil {
+					return fmt.Errorf("failed getting vega home: %w", err)
+				}
+				state.Settings.VegaHome = vegaHome
+			}
+
+			if utils.FileExists(state.Settings.VegaHome) {
+				state.CurrentState = StateExistingVegaHome
+			} else {
+				state.CurrentState = StateSelectTendermintHome
+			}
+
+		case StateExistingVegaHome:
+			if err := state.selectHomeAction(ui, "vega home", state.Settings.VegaHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateSelectTendermintHome
+
+		case StateSelectTendermintHome:
+			if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for tendermint home", state.Settings.TendermintHome)
+			} else {
+				tendermintHome, err := uilib.AskPath(ui, "tendermint home", state.Settings.TendermintHome)
+				if err != nil {
+					return fmt.Errorf("failed getting tendermint home: %w", err)
+				}
+				state.Settings.TendermintHome = tendermintHome
+			}
+
+			if utils.FileExists(state.Settings.TendermintHome) {
+				state.CurrentState = StateExistingTendermintHome
+			} else {
+				state.CurrentState = StateGetNodeWalletPassphrase
+			}
+
+		case StateExistingTendermintHome:
+			if err := state.selectHomeAction(ui, "tendermint home", state.Settings.TendermintHome); err != nil {
+				return err
+			}
+			state.CurrentState = StateGetNodeWalletPassphrase
+
+		case StateGetNodeWalletPassphrase:
+			state.Settings.RetainNodeWallets = false
+			if state.Settings.PreserveIdentity && utils.FileExists(state.Settings.VegaHome) {
+				retainNodeWallets, err := node.HomeHasNodeWallets(state.Settings.VegaHome)
+				if err != nil {
+					return fmt.Errorf("failed to check node wallets in the existing vega home: %w", err)
+				}
+				state.Settings.RetainNodeWallets = retainNodeWallets
+			}
+
+			// The retained wallets are encrypted with the existing passphrase, so the new one cannot be set
+			nextState := StateSelectVegaWallet
+			passphraseName := "node wallet passphrase"
+			if state.Settings.RetainNodeWallets {
+				nextState = StateGetEthereumRPCEndpoint
+				passphraseName = "passphrase of the node wallets retained from the existing vega home"
+			}
+
+			if state.Settings.NonInteractive {
+				if !utils.FileExists(state.Settings.NodeWalletPassphraseFile) {
+					if state.Settings.RetainNodeWallets {
+						return fmt.Errorf("node wallet passphrase file %s does not exist: it must contain the passphrase of the node wallets retained from %s", state.Settings.NodeWalletPassphraseFile, state.Settings.VegaHome)
+					}
+					return fmt.Errorf("node wallet passphrase file %s does not exist", state.Settings.NodeWalletPassphraseFile)
+				}
+				state.logger.Infof("NonInteractive: Using %s node wallet passphrase file", state.Settings.NodeWalletPassphraseFile)
+				state.CurrentState = nextState
+				continue
+			}
+
+			passphraseFile, err := uilib.AskPath(ui, fmt.Sprintf("file with the %s", passphraseName), state.Settings.NodeWalletPassphraseFile)
+			if err != nil {
+				return fmt.Errorf("failed getting node wallet passphrase file: %w", err)
+			}
+			state.Settings.NodeWalletPassphraseFile = passphraseFile
+
+			state.Settings.NodeWalletPassphrase = ""
+			if !utils.FileExists(passphraseFile) {
+				passphrase, err := AskPassphrase(ui, passphraseName)
+				if err != nil {
+					return fmt.Errorf("failed getting node wallet passphrase: %w", err)
+				}
+				state.Settings.NodeWalletPassphrase = passphrase
+			}
+			state.CurrentState = nextState
+
+		case StateSelectVegaWallet:
+			if state.Settings.NonInteractive {
+				if err := validateWalletSettings(state.Settings.VegaWallet); err != nil {
+					return fmt.Errorf("invalid vega wallet settings: %w", err)
+				}
+				state.logger.Infof("NonInteractive: Vega node wallet will be %sd", state.Settings.VegaWallet.Source)
+			} else {
+				wallet, err := AskWallet(ui, "vega", state.Settings.VegaWallet)
+				if err != nil {
+					return fmt.Errorf("failed getting vega wallet: %w", err)
+				}
+				state.Settings.VegaWallet = *wallet
+			}
+			state.CurrentState = StateSelectEthereumWallet
+
+		case StateSelectEthereumWallet:
+			if state.Settings.NonInteractive {
+				if err := validateWalletSettings(state.Settings.EthereumWallet); err != nil {
+					return fmt.Errorf("invalid ethereum wallet settings: %w", err)
+				}
+				state.logger.Infof("NonInteractive: Ethereum node wallet will be %sd", state.Settings.EthereumWallet.Source)
+			} else {
+				wallet, err := AskWallet(ui, "ethereum", state.Settings.EthereumWallet)
+				if err != nil {
+					return fmt.Errorf("failed getting ethereum wallet: %w", err)
+				}
+				state.Settings.EthereumWallet = *wallet
+			}
+			state.CurrentState = StateGetEthereumRPCEndpoint
+
+		case StateGetEthereumRPCEndpoint:
+			if state.Settings.NonInteractive {
+				if err := validateRPCEndpoint(state.Settings.EthereumRPCEndpoint); err != nil {
+					return fmt.Errorf("invalid ethereum rpc endpoint: %w", err)
+				}
+				state.logger.Infof("NonInteractive: Using %s ethereum rpc endpoint", state.Settings.EthereumRPCEndpoint)
+			} else {
+				endpoint, err := uilib.AskString(ui, "Ethereum RPC endpoint", state.Settings.EthereumRPCEndpoint, validateRPCEndpoint)
+				if err != nil {
+					return fmt.Errorf("failed getting ethereum rpc endpoint: %w", err)
+				}
+				state.Settings.EthereumRPCEndpoint = endpoint
+			}
+			state.CurrentState = StateGetEVMChains
+
+		case StateGetEVMChains:
+			if state.Settings.NonInteractive {
+				for _, chain := range state.Settings.EVMChains {
+					if err := validateRPCEndpoint(chain.RPCEndpoint); err != nil {
+						return fmt.Errorf("invalid rpc endpoint for the %s evm chain: %w", chain.ChainID, err)
+					}
+				}
+			} else {
+				chains, err := AskEVMChains(ui, state.Settings.EVMChains)
+				if err != nil {
+					return fmt.Errorf("failed getting evm chains: %w", err)
+				}
+				state.Settings.EVMChains = chains
+			}
+			state.CurrentState = StateCheckLatestVersion
+
+		case StateCheckLatestVersion:
+			statisticsResponse, err := apiClient.Statistics(context.Background())
+			if err != nil {
+				return fmt.Errorf("failed to get response for the /statistics endpoint from the %s servers: %w", networkConfig.Name, err)
+			}
+
+			vegaVersion, visorVersion, err := node.Versions(statisticsResponse, networkConfig, state.Settings.RequestedVegaVersion, state.Settings.Mode == StartFromBlock0)
+			if err != nil {
+				return fmt.Errorf("failed to get binaries versions: %w", err)
+			}
+
+			state.Settings.VegaBinaryVersion = vegaVersion
+			state.Settings.VisorBinaryVersion = visorVersion
+			state.Settings.VegaChainId = statisticsResponse.ChainID
+			state.CurrentState = StateSummary
+
+		case StateSummary:
+			printSummary(state.Settings, networkConfig.Name)
+
+			if state.Settings.NonInteractive {
+				state.logger.Info("NonInteractive: Moving to installation steps")
+
+				break STATE_RUN
+			}
+
+			correctResponse, err := uilib.AskYesNo(ui, "Is it correct?", uilib.AnswerYes)
+			if err != nil {
+				return fmt.Errorf("failed asking for correct summary: %w", err)
+			}
+
+			if correctResponse == uilib.AnswerNo {
+				state.CurrentState = StateSelectStartupMode
+				break
+			}
+
+			break STATE_RUN
+		}
+	}
+
+	return nil
+}
+
+// selectHomeAction asks what to do with the existing home. The selected action is executed by the generator.
+func (state *StateMachine) selectHomeAction(ui *input.UI, name, homePath string) error {
+	actions, err := node.SelectHomeAction(
+		state.logger,
+		ui,
+		state.Settings.NonInteractive,
+		state.Settings.RemoveExistingFiles,
+		state.Settings.HomeActions,
+		state.Settings.Backup.Action,
+		name,
+		homePath,
+	)
+	if err != nil {
+		return err
+	}
+	state.Settings.HomeActions = actions
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	input "github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/uilib"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+func SelectStartupMode(ui *input.UI, defaultValue StartupMode) (*StartupMode, error) {
+	const msg = `How do you want to start your validator node?
+
+  - Starting from block 0 - Starts the node from the genesis binary, replays all the blocks and
+                            does all of the protocol upgrades automatically.
+        * Depending on network age it can takes up to several days to catch your node up.
+
+  - Starting from snapshot - Start the node from latest binary and restore the state from the
+                             snapshot served by the network peers.
+        * It takes up to several minutes.`
+	response, err := ui.Select(
+		msg,
+		[]string{string(StartFromBlock0), string(StartFromSnapshot)},
+		&input.Options{
+			Default:  string(defaultValue),
+			Loop:     true,
+			Required: true,
+		},
+	)
+	if err != nil {
+		return nil, types.NewInputError(err)
+	}
+
+	result := StartFromSnapshot
+	if response == string(StartFromBlock0) {
+		result = StartFromBlock0
+	}
+
+	return &result, nil
+}
+
+func AskPassphrase(ui *input.UI, name string) (string, error) {
+	passphrase, err := ui.Ask(fmt.Sprintf("What is your %s", name), &input.Options{
+		Required: true,
+		Loop:     true,
+		Mask:     true,
+	})
+	if err != nil {
+		return "", types.NewInputError(err)
+	}
+
+	return passphrase, nil
+}
+
+func AskWallet(ui *input.UI, chain string, defaultValue WalletSettings) (*WalletSettings, error) {
+	source, err := ui.Select(
+		fmt.Sprintf("Do you want to generate a new %s node wallet or import the existing one?", chain),
+		[]string{string(WalletGenerate), string(WalletImport)},
+		&input.Options{
+			Default:  string(defaultValue.Source),
+			Loop:     true,
+			Required: true,
+		},
+	)
+	if err != nil {
+		return nil, types.NewInputError(err)
+	}
+
+	if source == string(WalletGenerate) {
+		return &WalletSettings{Source: WalletGenerate}, nil
+	}
+
+	walletPath, err := uilib.AskString(ui, fmt.Sprintf("Path to the %s wallet file", chain), defaultValue.Path, checkIfExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ask for %s wallet path: %w", chain, err)
+	}
+
+	passphraseFile, err := uilib.AskString(
+		ui,
+		fmt.Sprintf("Path to the file with passphrase for the %s wallet", chain),
+		defaultValue.PassphraseFile,
+		checkIfExists,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ask for %s wallet passphrase file: %w", chain, err)
+	}
+
+	return &WalletSettings{
+		Source:         WalletImport,
+		Path:           walletPath,
+		PassphraseFile: passphraseFile,
+	}, nil
+}
+
+func AskEVMChains(ui *input.UI, defaultValue []EVMChain) ([]EVMChain, error) {
+	defaultChains := []string{}
+	for _, chain := range defaultValue {
+		defaultChains = append(defaultChains, fmt.Sprintf("%s=%s", chain.ChainID, chain.RPCEndpoint))
+	}
+
+	answer, err := ui.Ask(
+		"RPC endpoints for additional EVM chains in the <chain-id>=<rpc-url> format, separated by comma. Leave empty if none",
+		&input.Options{
+			Default:  strings.Join(defaultChains, ","),
+			Required: false,
+			Loop:     true,
+			ValidateFunc: func(s string) error {
+				_, err := parseEVMChains(s)
+				return err
+			},
+		},
+	)
+	if err != nil {
+		return nil, types.NewInputError(err)
+	}
+
+	return parseEVMChains(answer)
+}
+
+func parseEVMChains(value string) ([]EVMChain, error) {
+	result := []EVMChain{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		chainID, endpoint, found := strings.Cut(item, "=")
+		if !found || chainID == "" {
+			return nil, fmt.Errorf("invalid evm chain '%s': expected <chain-id>=<rpc-url>", item)
+		}
+
+		if err := validateRPCEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+
+		result = append(result, EVMChain{ChainID: chainID, RPCEndpoint: endpoint})
+	}
+
+	return result, nil
+}
+
+func validateRPCEndpoint(endpoint string) error {
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid rpc endpoint '%s': %w", endpoint, err)
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("invalid rpc endpoint '%s': expected http, https, ws or wss scheme", endpoint)
+	}
+
+	if parsedURL.Hostname() == "" {
+		return fmt.Errorf("invalid rpc endpoint '%s': missing host", endpoint)
+	}
+
+	return nil
+}
+
+func validateWalletSettings(settings WalletSettings) error {
+	switch settings.Source {
+	case WalletGenerate:
+		return nil
+	case WalletImport:
+		if err := checkIfExists(settings.Path); err != nil {
+			return fmt.Errorf("invalid wallet path: %w", err)
+		}
+		if err := checkIfExists(settings.PassphraseFile); err != nil {
+			return fmt.Errorf("invalid wallet passphrase file: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("invalid wallet source '%s': expected %s or %s", settings.Source, WalletGenerate, WalletImport)
+}
+
+func checkIfExists(filePath string) error {
+	if !utils.FileExists(filePath) {
+		return fmt.Errorf("file %s does not exist", filePath)
+	}
+
+	return nil
+}
+
+func printSummary(settings GenerateSettings, networkName string) {
+	fmt.Print("\n Summary:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	tbl := table.New("Parameter", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("Network", networkName)
+	if settings.Mode == StartFromBlock0 {
+		tbl.AddRow("Mode", "Start from block 0")
+	} else {
+		tbl.AddRow("Mode", "Start from snapshot")
+	}
+	tbl.AddRow("Visor Home", settings.VisorHome)
+	tbl.AddRow("Vega Home", settings.VegaHome)
+	tbl.AddRow("Tendermint Home", settings.TendermintHome)
+	tbl.AddRow("Preserve Identity", settings.PreserveIdentity)
+	tbl.AddRow("Node Wallet Passphrase File", settings.NodeWalletPassphraseFile)
+	if settings.RetainNodeWallets {
+		tbl.AddRow("Node Wallets", "Retained from the existing vega home")
+	} else {
+		tbl.AddRow("Vega Wallet", walletSummary(settings.VegaWallet))
+		tbl.AddRow("Ethereum Wallet", walletSummary(settings.EthereumWallet))
+	}
+	tbl.AddRow("Ethereum RPC Endpoint", settings.EthereumRPCEndpoint)
+	for _, chain := range settings.EVMChains {
+		tbl.AddRow(fmt.Sprintf("EVM Chain %s RPC Endpoint", chain.ChainID), chain.RPCEndpoint)
+	}
+	tbl.AddRow("Vega Version", settings.VegaBinaryVersion)
+	tbl.AddRow("Vega Chain ID", settings.VegaChainId)
+
+	tbl.Print()
+	fmt.Println("")
+}
+
+func walletSummary(settings WalletSettings) string {
+	if settings.Source == WalletImport {
+		return fmt.Sprintf("Import from %s", settings.Path)
+	}
+
+	return "Generate new"
+}
+
+func PrintInstructions(visorHome, nodeWallets string) {
+	fmt.Printf(`
+    The validator node is initialized. Your node wallets:
+
+%s
+
+    You can now start it with the following command:
+
+      %s/visor run --home %s
+
+    Remember to keep the node wallet passphrase file and the node wallets safe.
+    You can also setup systemd service if you running your node on LINUX with the following command:
+
+      sudo vega-assistant setup systemd --visor-home %s
+`, nodeWallets, visorHome, visorHome, visorHome)
+}
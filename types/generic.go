@@ -1,9 +1,31 @@
 package types
 
+import (
+	"fmt"
+	"os"
+)
+
 type SQLCredentials struct {
-	Host         string `toml:"host"`
-	User         string `toml:"user"`
-	Port         int    `toml:"port"`
-	Pass         string `toml:"pass"`
+	Host string `toml:"host"`
+	User string `toml:"user"`
+	Port int    `toml:"port"`
+	Pass string `toml:"pass"`
+	// PassEnv is the name of the environment variable with the password. Used when the Pass is empty.
+	PassEnv      string `toml:"pass-env,omitempty"`
 	DatabaseName string `toml:"db-name"`
 }
+
+// ResolvePassword reads the password from the PassEnv environment variable when the password is not given explicitly
+func (creds *SQLCredentials) ResolvePassword() error {
+	if creds.Pass != "" || creds.PassEnv == "" {
+		return nil
+	}
+
+	password, ok := os.LookupEnv(creds.PassEnv)
+	if !ok || password == "" {
+		return fmt.Errorf("the %s environment variable with the SQL password is not set", creds.PassEnv)
+	}
+	creds.Pass = password
+
+	return nil
+}
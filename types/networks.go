@@ -2,6 +2,6 @@ package types
 
 // REST endpoint is used to check if network is up to date
 type EndpointWithVegaREST struct {
-	REST     string
-	Endpoint string
+	REST     string `toml:"rest" json:"rest"`
+	Endpoint string `toml:"endpoint" json:"endpoint"`
 }
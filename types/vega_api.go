@@ -46,3 +46,8 @@ type NetworkHistorySegment struct {
 type NetworkHistorySegments struct {
 	Segments []NetworkHistorySegment `json:"segments"`
 }
+
+type TendermintSyncInfo struct {
+	BlockHeight uint64
+	CatchingUp  bool
+}
@@ -33,18 +33,6 @@ func AskPath(ui *input.UI, name, defaultValue string) (string, error) {
 	return response, nil
 }
 
-func AskRemoveExistingFile(
-	ui *input.UI,
-	filePath string,
-	defaultAnswer YesNoAnswer,
-) (YesNoAnswer, error) {
-	return AskYesNo(
-		ui,
-		fmt.Sprintf("File %s exists. Do you want to remove it?", filePath),
-		defaultAnswer,
-	)
-}
-
 func AskString(
 	ui *input.UI,
 	question string,
@@ -1,35 +1,172 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 )
 
+// DownloadOptions controls timeouts, retries and progress output of the DownloadFileWithOptions
+type DownloadOptions struct {
+	// ConnectTimeout is the maximum time to connect and receive the response headers
+	ConnectTimeout time.Duration
+	// IdleTimeout aborts the download when no bytes are received for the given time
+	IdleTimeout time.Duration
+	Retries     int
+	RetryDelay  time.Duration
+	// Resume continues the download of the existing destination file. Otherwise, the file is
+	// overwritten and the resume is used only between retries.
+	Resume bool
+	// Progress is the writer for the progress bar. No progress is printed when it is nil.
+	Progress io.Writer
+}
+
+func DefaultDownloadOptions() DownloadOptions {
+	return DownloadOptions{
+		ConnectTimeout: 30 * time.Second,
+		IdleTimeout:    time.Minute,
+		Retries:        5,
+		RetryDelay:     3 * time.Second,
+	}
+}
+
 func DownloadFile(url, dst string) error {
-	resp, err := http.Get(url)
+	return DownloadFileWithOptions(url, dst, DefaultDownloadOptions())
+}
+
+// DownloadFileWithOptions downloads the file with retries. The failed attempt is resumed from the end of
+// the destination file with the HTTP Range request, so the interrupted download does not start from zero.
+func DownloadFileWithOptions(url, dst string, options DownloadOptions) error {
+	if !options.Resume {
+		if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove existing destination file: %w", err)
+		}
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout: options.ConnectTimeout,
+			}).DialContext,
+			TLSHandshakeTimeout:   options.ConnectTimeout,
+			ResponseHeaderTimeout: options.ConnectTimeout,
+		},
+	}
+
+	err := RetryRun(options.Retries, options.RetryDelay, func() error {
+		return downloadChunk(client, url, dst, options)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return fmt.Errorf("failed to download file from %s: %w", url, err)
 	}
 
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status code is not 200")
+	return nil
+}
+
+func downloadChunk(client *http.Client, url, dst string, options DownloadOptions) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	// Create the file
-	out, err := os.Create(dst)
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE, 0o644)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer out.Close()
 
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek destination file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// Server does not support ranges or there is nothing to resume, start from scratch
+		if err := out.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate destination file: %w", err)
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek destination file: %w", err)
+		}
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The local file is bigger than the remote one, it cannot be resumed
+		if err := out.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate destination file: %w", err)
+		}
+		return fmt.Errorf("cannot resume download: %s", resp.Status)
+	default:
+		return fmt.Errorf("bad http status: %s", resp.Status)
+	}
+
+	var body io.Reader = newIdleTimeoutReader(resp.Body, options.IdleTimeout, cancel)
+	if options.Progress != nil {
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+		progress := newProgressReader(body, options.Progress, filepath.Base(dst), offset, total)
+		defer progress.Finish()
+		body = progress
+	}
+
+	if _, err := io.Copy(out, body); err != nil {
 		return fmt.Errorf("failed to copy downloaded body to dst file: %w", err)
 	}
 
 	return nil
 }
+
+// idleTimeoutReader cancels the request when no data arrives within the timeout
+type idleTimeoutReader struct {
+	reader io.Reader
+	timer  *time.Timer
+	after  time.Duration
+}
+
+func newIdleTimeoutReader(reader io.Reader, timeout time.Duration, cancel context.CancelFunc) io.Reader {
+	if timeout <= 0 {
+		return reader
+	}
+
+	return &idleTimeoutReader{
+		reader: reader,
+		timer:  time.AfterFunc(timeout, cancel),
+		after:  timeout,
+	}
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil {
+		r.timer.Stop()
+		return n, err
+	}
+	r.timer.Reset(r.after)
+
+	return n, err
+}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -35,3 +39,57 @@ func IsWSL() bool {
 	return strings.Contains(string(versionContent), "microsoft") ||
 		strings.Contains(string(versionContent), "WSL")
 }
+
+// CacheDirPath returns path inside the vega-assistant directory in the user cache dir
+func CacheDirPath(elem ...string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(CurrentUserHomePath(), ".cache")
+	}
+
+	return filepath.Join(append([]string{cacheDir, "vega-assistant"}, elem...)...)
+}
+
+// TotalMemory returns the total memory of the host in bytes
+func TotalMemory() (uint64, error) {
+	if runtime.GOOS == "darwin" {
+		output, err := ExecuteBinary("sysctl", []string{"-n", "hw.memsize"}, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get total memory: %w", err)
+		}
+
+		memory, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse total memory(%s): %w", output, err)
+		}
+
+		return memory, nil
+	}
+
+	memInfo, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open /proc/meminfo: %w", err)
+	}
+	defer memInfo.Close()
+
+	scanner := bufio.NewScanner(memInfo)
+	for scanner.Scan() {
+		// The line has the following format: MemTotal:       32657512 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+
+		memoryKB, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse total memory(%s): %w", fields[1], err)
+		}
+
+		return memoryKB * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read /proc/meminfo: %w", err)
+	}
+
+	return 0, fmt.Errorf("total memory not found in /proc/meminfo")
+}
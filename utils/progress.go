@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth      = 30
+	progressRefreshPeriod = 200 * time.Millisecond
+)
+
+// progressReader prints the progress bar and the download speed while the data is read
+type progressReader struct {
+	reader io.Reader
+	output io.Writer
+	name   string
+
+	startOffset int64
+	current     int64
+	total       int64
+
+	started     time.Time
+	lastPrinted time.Time
+}
+
+func newProgressReader(reader io.Reader, output io.Writer, name string, offset, total int64) *progressReader {
+	return &progressReader{
+		reader:      reader,
+		output:      output,
+		name:        name,
+		startOffset: offset,
+		current:     offset,
+		total:       total,
+		started:     time.Now(),
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.current += int64(n)
+
+	if time.Since(r.lastPrinted) >= progressRefreshPeriod {
+		r.print()
+	}
+
+	return n, err
+}
+
+// Finish prints the final state of the progress and moves to the new line
+func (r *progressReader) Finish() {
+	r.print()
+	fmt.Fprintln(r.output)
+}
+
+func (r *progressReader) print() {
+	r.lastPrinted = time.Now()
+
+	speed := 0.0
+	if elapsed := time.Since(r.started).Seconds(); elapsed > 0 {
+		speed = float64(r.current-r.startOffset) / elapsed
+	}
+
+	if r.total <= 0 {
+		fmt.Fprintf(r.output, "\r%s %s %s/s   ", r.name, formatBytes(r.current), formatBytes(int64(speed)))
+		return
+	}
+
+	ratio := float64(r.current) / float64(r.total)
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * progressBarWidth)
+
+	fmt.Fprintf(
+		r.output,
+		"\r%s [%s%s] %3.0f%% %s/%s %s/s   ",
+		r.name,
+		strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled),
+		ratio*100,
+		formatBytes(r.current),
+		formatBytes(r.total),
+		formatBytes(int64(speed)),
+	)
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
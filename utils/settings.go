@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Settings are the answers for the setup command which can be provided in the config file
+type Settings interface {
+	// Validate checks the format of the values. It does not check the state of the machine, e.g. if homes exist.
+	Validate() error
+}
+
+// LoadSettings reads the toml config file into the settings. The settings should be prefilled with
+// the default values, only the values present in the file are overridden.
+func LoadSettings(filePath string, settings Settings) error {
+	tomlTree, err := toml.LoadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	if err := tomlTree.Unmarshal(settings); err != nil {
+		return fmt.Errorf("failed to unmarshal config file: %w", err)
+	}
+
+	return nil
+}
+
+// ValidatePort checks if the port is in the valid TCP port range
+func ValidatePort(name string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be between 1 and 65535, got %d", name, port)
+	}
+
+	return nil
+}
+
+// ValidateNotEmpty checks if all the given values are not empty. The keys are names reported in the error.
+func ValidateNotEmpty(values map[string]string) error {
+	for name, value := range values {
+		if value == "" {
+			return fmt.Errorf("%s cannot be empty", name)
+		}
+	}
+
+	return nil
+}
+
+// SaveSettings writes the settings to the toml file, which can be later read with the LoadSettings.
+// The file may contain secrets, so it is readable only by the owner.
+func SaveSettings(filePath string, settings Settings) error {
+	content, err := toml.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", filePath, err)
+	}
+
+	return nil
+}
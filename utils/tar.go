@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TarGzDir writes content of the directory into the tar.gz archive. The name is stored in the gzip header,
+// it can be read with the TarGzName. Files for which the skip function returns true are not archived.
+func TarGzDir(srcDir, archivePath, name string, skip func(relPath string) bool) error {
+	archiveFile, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive %s: %w", archivePath, err)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	gzipWriter.Name = name
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = filepath.WalkDir(srcDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(srcDir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+		if skip != nil && skip(relPath) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", srcDir, err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close tar archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip archive: %w", err)
+	}
+
+	return nil
+}
+
+// TarGzName returns the name stored in the gzip header of the archive
+func TarGzName(archivePath string) (string, error) {
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer archiveFile.Close()
+
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read gzip archive %s: %w", archivePath, err)
+	}
+	defer gzipReader.Close()
+
+	return gzipReader.Name, nil
+}
+
+// UntarGz extracts the tar.gz archive into the destination directory. Symlinks must point inside the destination
+// directory. Absolute symlinks may also point inside the linkRoot, the directory the extracted files are moved to
+// afterwards. Entries are never written through the extracted symlinks.
+func UntarGz(archivePath, dst, linkRoot string) error {
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer archiveFile.Close()
+
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return fmt.Errorf("failed to read gzip archive %s: %w", archivePath, err)
+	}
+	defer gzipReader.Close()
+
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dst, err)
+	}
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		filePath := filepath.Join(dst, header.Name)
+		if !isWithinDir(filePath, dst) {
+			return fmt.Errorf(
+				"cannot unarchive file that destination is outside of the output directory",
+			)
+		}
+		if err := ensureNoSymlinkInPath(dst, filePath); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to make parent dir for file %s: %w", filePath, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(filePath, os.FileMode(header.Mode)); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filePath, err)
+			}
+		case tar.TypeSymlink:
+			linkTarget := header.Linkname
+			withinRoot := false
+			if filepath.IsAbs(linkTarget) {
+				withinRoot = linkRoot != "" && (isWithinDir(linkTarget, linkRoot) || filepath.Clean(linkTarget) == filepath.Clean(linkRoot))
+			} else {
+				linkTarget = filepath.Join(filepath.Dir(filePath), linkTarget)
+			}
+			if !withinRoot && !isWithinDir(linkTarget, dst) && filepath.Clean(linkTarget) != filepath.Clean(dst) {
+				return fmt.Errorf("cannot unarchive symlink %s pointing outside of the output directory: %s", header.Name, header.Linkname)
+			}
+			if err := os.Symlink(header.Linkname, filePath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", filePath, err)
+			}
+		case tar.TypeReg:
+			if err := extractTarFile(tarReader, filePath, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isWithinDir returns true when the path is inside the directory
+func isWithinDir(path, dir string) bool {
+	return strings.HasPrefix(filepath.Clean(path), filepath.Clean(dir)+string(os.PathSeparator))
+}
+
+// ensureNoSymlinkInPath checks neither the file nor its parents inside the root is a symlink, so the file
+// cannot be written through the symlink extracted earlier
+func ensureNoSymlinkInPath(root, filePath string) error {
+	root = filepath.Clean(root)
+	for dir := filepath.Clean(filePath); isWithinDir(dir, root); dir = filepath.Dir(dir) {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to check %s: %w", dir, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("cannot unarchive %s through the symlink %s", filePath, dir)
+		}
+	}
+
+	return nil
+}
+
+func extractTarFile(reader io.Reader, filePath string, mode os.FileMode) error {
+	dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, reader); err != nil {
+		return fmt.Errorf("failed to copy file content from archive to %s: %w", filePath, err)
+	}
+
+	return nil
+}
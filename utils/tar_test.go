@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	content  string
+}
+
+func writeTestTarGz(t *testing.T, archivePath string, entries []tarEntry) {
+	t.Helper()
+
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %s", err)
+	}
+	defer archiveFile.Close()
+
+	gzipWriter := gzip.NewWriter(archiveFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0o755,
+			Size:     int64(len(entry.content)),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write tar header: %s", err)
+		}
+		if entry.typeflag == tar.TypeReg {
+			if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+				t.Fatalf("failed to write tar content: %s", err)
+			}
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %s", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %s", err)
+	}
+}
+
+func TestUntarGz(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []tarEntry
+		linkRoot func(dst string) string
+		wantErr  string
+	}{
+		{
+			name: "valid archive",
+			entries: []tarEntry{
+				{name: "config", typeflag: tar.TypeDir},
+				{name: "config/node.toml", typeflag: tar.TypeReg, content: "x"},
+				{name: "node.toml", typeflag: tar.TypeSymlink, linkname: "config/node.toml"},
+			},
+		},
+		{
+			name: "absolute symlink within the link root",
+			entries: []tarEntry{
+				{name: "current", typeflag: tar.TypeSymlink, linkname: "/opt/vega/visor_home/genesis"},
+			},
+			linkRoot: func(string) string { return "/opt/vega/visor_home" },
+		},
+		{
+			name: "parent directory path",
+			entries: []tarEntry{
+				{name: "../escaped.txt", typeflag: tar.TypeReg, content: "x"},
+			},
+			wantErr: "destination is outside of the output directory",
+		},
+		{
+			name: "nested parent directory path",
+			entries: []tarEntry{
+				{name: "config/../../escaped.txt", typeflag: tar.TypeReg, content: "x"},
+			},
+			wantErr: "destination is outside of the output directory",
+		},
+		{
+			name: "absolute symlink outside of the output directory",
+			entries: []tarEntry{
+				{name: "passwd", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+			},
+			wantErr: "cannot unarchive symlink passwd pointing outside of the output directory",
+		},
+		{
+			name: "absolute symlink without link root",
+			entries: []tarEntry{
+				{name: "current", typeflag: tar.TypeSymlink, linkname: "/opt/vega/visor_home/genesis"},
+			},
+			wantErr: "cannot unarchive symlink current pointing outside of the output directory",
+		},
+		{
+			name: "relative symlink escaping the output directory",
+			entries: []tarEntry{
+				{name: "config", typeflag: tar.TypeDir},
+				{name: "config/escape", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+			},
+			wantErr: "cannot unarchive symlink config/escape pointing outside of the output directory",
+		},
+		{
+			name: "file written through the extracted symlink",
+			entries: []tarEntry{
+				{name: "config", typeflag: tar.TypeDir},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "config"},
+				{name: "link/node.toml", typeflag: tar.TypeReg, content: "x"},
+			},
+			wantErr: "through the symlink",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			archivePath := filepath.Join(workDir, "home.tar.gz")
+			dst := filepath.Join(workDir, "out", "home")
+			writeTestTarGz(t, archivePath, tt.entries)
+
+			linkRoot := ""
+			if tt.linkRoot != nil {
+				linkRoot = tt.linkRoot(dst)
+			}
+
+			err := UntarGz(archivePath, dst, linkRoot)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+			for _, path := range []string{
+				filepath.Join(workDir, "out", "escaped.txt"),
+				filepath.Join(workDir, "escaped.txt"),
+				filepath.Join(dst, "config", "node.toml"),
+			} {
+				if _, err := os.Lstat(path); err == nil {
+					t.Errorf("file %s must not be extracted", path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package vega
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pg "github.com/go-pg/pg/v11"
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+// TimescaleVersion is the only TimescaleDB extension version supported by the data-node
+const TimescaleVersion = "2.8.0"
+
+// CheckSQLCredentials connects to the PostgreSQL and checks if the TimescaleDB version supported by the data-node is installed
+func CheckSQLCredentials(creds types.SQLCredentials) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := connect(creds, creds.DatabaseName)
+	defer db.Close(ctx)
+
+	if err := ping(ctx, db); err != nil {
+		return err
+	}
+
+	var timescaleVersion string
+	_, err := db.QueryOne(
+		ctx,
+		pg.Scan(&timescaleVersion),
+		`SELECT COALESCE(installed_version, default_version) AS extversion FROM pg_available_extensions WHERE name = 'timescaledb' LIMIT 1;`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check timescale extension version: %w", err)
+	}
+
+	if !strings.HasPrefix(timescaleVersion, "v") {
+		timescaleVersion = fmt.Sprintf("v%s", timescaleVersion)
+	}
+
+	if semver.Compare(timescaleVersion, "v"+TimescaleVersion) != 0 {
+		return fmt.Errorf(
+			"Vega support only timescale v%s. Installed version is %s",
+			TimescaleVersion,
+			timescaleVersion,
+		)
+	}
+
+	return nil
+}
+
+// ProvisionDatabase connects to the PostgreSQL server with the superuser credentials, creates the role and
+// the database described by the creds and installs the TimescaleDB extension in the database. Existing role
+// and database are refused, unless updateExisting is set. Then the role password is reset to the given one
+// and the role becomes the owner of the database.
+func ProvisionDatabase(logger *zap.SugaredLogger, superuser, creds types.SQLCredentials, updateExisting bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	db := connect(superuser, superuser.DatabaseName)
+	defer db.Close(ctx)
+
+	var availableVersion string
+	_, err := db.QueryOne(
+		ctx,
+		pg.Scan(&availableVersion),
+		`SELECT COALESCE(MAX(version), '') FROM pg_available_extension_versions WHERE name = 'timescaledb' AND version = ?`,
+		TimescaleVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check available timescale extension versions: %w", err)
+	}
+	if availableVersion == "" {
+		return fmt.Errorf(
+			"TimescaleDB v%s is not available on the server: install it and add timescaledb to the shared_preload_libraries",
+			TimescaleVersion,
+		)
+	}
+
+	roleExists, databaseExists, err := existingSQLObjects(ctx, db, creds)
+	if err != nil {
+		return err
+	}
+	if !updateExisting && (roleExists || databaseExists) {
+		return fmt.Errorf("the role or the database already exists on the server and updating them is not allowed")
+	}
+
+	if roleExists {
+		logger.Infof("Role %s already exists. Updating its password", creds.User)
+		if _, err := db.Exec(ctx, `ALTER ROLE ? WITH LOGIN PASSWORD ?`, pg.Ident(creds.User), creds.Pass); err != nil {
+			return fmt.Errorf("failed to update role %s: %w", creds.User, err)
+		}
+	} else {
+		logger.Infof("Creating role %s", creds.User)
+		if _, err := db.Exec(ctx, `CREATE ROLE ? WITH LOGIN PASSWORD ?`, pg.Ident(creds.User), creds.Pass); err != nil {
+			return fmt.Errorf("failed to create role %s: %w", creds.User, err)
+		}
+	}
+
+	if databaseExists {
+		logger.Infof("Database %s already exists. Changing its owner to %s", creds.DatabaseName, creds.User)
+		if _, err := db.Exec(ctx, `ALTER DATABASE ? OWNER TO ?`, pg.Ident(creds.DatabaseName), pg.Ident(creds.User)); err != nil {
+			return fmt.Errorf("failed to change owner of database %s: %w", creds.DatabaseName, err)
+		}
+	} else {
+		logger.Infof("Creating database %s", creds.DatabaseName)
+		if _, err := db.Exec(ctx, `CREATE DATABASE ? OWNER ?`, pg.Ident(creds.DatabaseName), pg.Ident(creds.User)); err != nil {
+			return fmt.Errorf("failed to create database %s: %w", creds.DatabaseName, err)
+		}
+	}
+
+	if _, err := db.Exec(ctx, `GRANT ALL PRIVILEGES ON DATABASE ? TO ?`, pg.Ident(creds.DatabaseName), pg.Ident(creds.User)); err != nil {
+		return fmt.Errorf("failed to grant privileges on database %s: %w", creds.DatabaseName, err)
+	}
+
+	// The extension is created in the data-node database, so we need a new connection
+	vegaDB := connect(superuser, creds.DatabaseName)
+	defer vegaDB.Close(ctx)
+
+	var installedVersion string
+	_, err = vegaDB.QueryOne(
+		ctx,
+		pg.Scan(&installedVersion),
+		`SELECT COALESCE(MAX(extversion), '') FROM pg_extension WHERE extname = 'timescaledb'`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check installed timescale extension: %w", err)
+	}
+
+	switch installedVersion {
+	case "":
+		logger.Infof("Creating timescaledb v%s extension in the %s database", TimescaleVersion, creds.DatabaseName)
+		if _, err := vegaDB.Exec(ctx, `CREATE EXTENSION timescaledb WITH VERSION ?`, TimescaleVersion); err != nil {
+			return fmt.Errorf("failed to create timescaledb extension: %w", err)
+		}
+	case TimescaleVersion:
+		logger.Infof("The timescaledb v%s extension is already installed in the %s database", TimescaleVersion, creds.DatabaseName)
+	default:
+		return fmt.Errorf(
+			"the timescaledb v%s extension is installed in the %s database, but vega supports only v%s: drop the extension or use a different database",
+			installedVersion,
+			creds.DatabaseName,
+			TimescaleVersion,
+		)
+	}
+
+	if _, err := vegaDB.Exec(ctx, `GRANT ALL ON SCHEMA public TO ?`, pg.Ident(creds.User)); err != nil {
+		return fmt.Errorf("failed to grant privileges on the public schema: %w", err)
+	}
+
+	return nil
+}
+
+// ExistingSQLObjects checks if the role and the database described by the creds already exist on the server
+func ExistingSQLObjects(superuser, creds types.SQLCredentials) (bool, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := connect(superuser, superuser.DatabaseName)
+	defer db.Close(ctx)
+
+	return existingSQLObjects(ctx, db, creds)
+}
+
+func existingSQLObjects(ctx context.Context, db *pg.DB, creds types.SQLCredentials) (bool, bool, error) {
+	var roleExists bool
+	if _, err := db.QueryOne(ctx, pg.Scan(&roleExists), `SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = ?)`, creds.User); err != nil {
+		return false, false, fmt.Errorf("failed to check if role %s exists: %w", creds.User, err)
+	}
+
+	var databaseExists bool
+	if _, err := db.QueryOne(ctx, pg.Scan(&databaseExists), `SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = ?)`, creds.DatabaseName); err != nil {
+		return false, false, fmt.Errorf("failed to check if database %s exists: %w", creds.DatabaseName, err)
+	}
+
+	return roleExists, databaseExists, nil
+}
+
+// PingSQL checks the PostgreSQL server accepts connections with the given credentials
+func PingSQL(creds types.SQLCredentials) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db := connect(creds, creds.DatabaseName)
+	defer db.Close(ctx)
+
+	return ping(ctx, db)
+}
+
+func ping(ctx context.Context, db *pg.DB) error {
+	var n int
+	_, err := db.QueryOne(ctx, pg.Scan(&n), "SELECT 1")
+
+	return err
+}
+
+func connect(creds types.SQLCredentials, database string) *pg.DB {
+	return pg.Connect(&pg.Options{
+		Addr:     fmt.Sprintf("%s:%d", creds.Host, creds.Port),
+		User:     creds.User,
+		Password: creds.Pass,
+		Database: database,
+	})
+}
@@ -364,3 +364,13 @@ func (n *NetworkAPI) getNetworkHistorySegments(ctx context.Context, endpoint str
 
 	return &result, nil
 }
+
+// LatestStatistics returns statistics from the endpoint with the highest block, it is the network head
+func (n *NetworkAPI) LatestStatistics(ctx context.Context) (*types.VegaStatistics, error) {
+	return getLatestStatistics(ctx, n.httpClient, n.apiREST)
+}
+
+// NodeStatistics returns statistics of the single node, e.g. the local core or data-node REST API
+func (n *NetworkAPI) NodeStatistics(ctx context.Context, restURL string) (*types.VegaStatistics, error) {
+	return getStatistics(ctx, n.httpClient, restURL)
+}
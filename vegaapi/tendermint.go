@@ -0,0 +1,117 @@
+package vegaapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+type tendermintStatus struct {
+	Result struct {
+		NodeInfo struct {
+			ID         string `json:"id"`
+			ListenAddr string `json:"listen_addr"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string `json:"latest_block_height"`
+			LatestBlockTime   string `json:"latest_block_time"`
+			CatchingUp        bool   `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+// TendermintSyncInfo returns the latest block known by the tendermint and whether it is still catching up
+func (n *NetworkAPI) TendermintSyncInfo(ctx context.Context, rpcServer string) (*types.TendermintSyncInfo, error) {
+	status, err := n.tendermintStatus(ctx, rpcServer)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHeight, err := strconv.ParseUint(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tendermint block height: %w", err)
+	}
+
+	return &types.TendermintSyncInfo{
+		BlockHeight: blockHeight,
+		CatchingUp:  status.Result.SyncInfo.CatchingUp,
+	}, nil
+}
+
+func (n *NetworkAPI) tendermintStatus(ctx context.Context, rpcServer string) (*tendermintStatus, error) {
+	rpcURL := rpcServer
+	if !strings.Contains(rpcURL, "://") {
+		rpcURL = fmt.Sprintf("http://%s", rpcURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/status", strings.TrimRight(rpcURL, "/")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status request for %s: %w", rpcServer, err)
+	}
+
+	status := &tendermintStatus{}
+	if err := n.httpCall(req, status); err != nil {
+		return nil, fmt.Errorf("failed to get tendermint status from %s: %w", rpcServer, err)
+	}
+
+	return status, nil
+}
+
+// TendermintPeers asks given tendermint RPC servers about their node IDs and returns them in
+// the `<node-id>@<host>:<p2p-port>` format accepted by the p2p.seeds and p2p.persistent_peers.
+func (n *NetworkAPI) TendermintPeers(ctx context.Context, rpcServers []string) ([]string, error) {
+	var resErr error
+
+	result := []string{}
+	for _, rpcServer := range rpcServers {
+		peer, err := n.tendermintPeer(ctx, rpcServer)
+		if err != nil {
+			resErr = multierror.Append(resErr, err)
+			continue
+		}
+
+		result = append(result, peer)
+	}
+
+	if len(result) < 1 {
+		return nil, fmt.Errorf("failed to get node id from any tendermint rpc server: %w", resErr)
+	}
+
+	return result, nil
+}
+
+func (n *NetworkAPI) tendermintPeer(ctx context.Context, rpcServer string) (string, error) {
+	rpcURL := rpcServer
+	if !strings.Contains(rpcURL, "://") {
+		rpcURL = fmt.Sprintf("http://%s", rpcURL)
+	}
+
+	parsedURL, err := url.Parse(rpcURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tendermint rpc address %s: %w", rpcServer, err)
+	}
+
+	status, err := n.tendermintStatus(ctx, rpcServer)
+	if err != nil {
+		return "", err
+	}
+
+	if status.Result.NodeInfo.ID == "" {
+		return "", fmt.Errorf("empty node id returned by %s", rpcServer)
+	}
+
+	p2pPort := "26656"
+	if listenAddr, err := url.Parse(status.Result.NodeInfo.ListenAddr); err == nil && listenAddr.Port() != "" {
+		p2pPort = listenAddr.Port()
+	}
+
+	return fmt.Sprintf("%s@%s", status.Result.NodeInfo.ID, net.JoinHostPort(parsedURL.Hostname(), p2pPort)), nil
+}
@@ -0,0 +1,96 @@
+package vegacmd
+
+import (
+	"fmt"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+type NodeWalletChain string
+
+const (
+	NodeWalletChainVega       NodeWalletChain = "vega"
+	NodeWalletChainEthereum   NodeWalletChain = "ethereum"
+	NodeWalletChainTendermint NodeWalletChain = "tendermint"
+)
+
+func GenerateNodeWallet(binaryPath, vegaHome, passphraseFile string, chain NodeWalletChain) error {
+	_, err := utils.ExecuteBinary(
+		binaryPath,
+		[]string{
+			"nodewallet", "generate",
+			"--chain", string(chain),
+			"--home", vegaHome,
+			"--passphrase-file", passphraseFile,
+			"--output", "json",
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s node wallet: %w", chain, err)
+	}
+
+	return nil
+}
+
+func ImportNodeWallet(
+	binaryPath, vegaHome, passphraseFile string,
+	chain NodeWalletChain,
+	walletPath, walletPassphraseFile string,
+) error {
+	_, err := utils.ExecuteBinary(
+		binaryPath,
+		[]string{
+			"nodewallet", "import",
+			"--chain", string(chain),
+			"--home", vegaHome,
+			"--passphrase-file", passphraseFile,
+			"--wallet-path", walletPath,
+			"--wallet-passphrase-file", walletPassphraseFile,
+			"--output", "json",
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import %s node wallet: %w", chain, err)
+	}
+
+	return nil
+}
+
+func ImportTendermintNodeWallet(binaryPath, vegaHome, passphraseFile, tendermintHome string) error {
+	_, err := utils.ExecuteBinary(
+		binaryPath,
+		[]string{
+			"nodewallet", "import",
+			"--chain", string(NodeWalletChainTendermint),
+			"--home", vegaHome,
+			"--passphrase-file", passphraseFile,
+			"--tendermint-home", tendermintHome,
+			"--output", "json",
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import tendermint node wallet: %w", err)
+	}
+
+	return nil
+}
+
+func ShowNodeWallets(binaryPath, vegaHome, passphraseFile string) (string, error) {
+	output, err := utils.ExecuteBinary(
+		binaryPath,
+		[]string{
+			"nodewallet", "show",
+			"--home", vegaHome,
+			"--passphrase-file", passphraseFile,
+		},
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to show node wallets: %w", err)
+	}
+
+	return string(output), nil
+}
@@ -2,6 +2,7 @@ package vegacmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/daniel1302/vega-assistant/utils"
 )
@@ -14,3 +15,13 @@ func InitTendermint(binaryPath, tendermintHome string) error {
 
 	return nil
 }
+
+// TendermintNodeID returns the id of the tendermint node key stored in the tendermint home
+func TendermintNodeID(binaryPath, tendermintHome string) (string, error) {
+	out, err := utils.ExecuteBinary(binaryPath, []string{"tm", "show-node-id", "--home", tendermintHome}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tendermint node id: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -18,3 +18,22 @@ func InitVega(binaryPath, vegaHome string, nodeMode VegaNodeMode) error {
 
 	return nil
 }
+
+func InitValidator(binaryPath, vegaHome, nodeWalletPassphraseFile string) error {
+	_, err := utils.ExecuteBinary(
+		binaryPath,
+		[]string{
+			"init",
+			"--output", "json",
+			"--home", vegaHome,
+			"--nodewallet-passphrase-file", nodeWalletPassphraseFile,
+			string(VegaNodeValidator),
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init vega validator: %w", err)
+	}
+
+	return nil
+}
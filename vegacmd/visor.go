@@ -13,15 +13,23 @@ const VisorRunConfigTemplate = `name = "{{.Version}}"
 [vega]
   [vega.binary]
     path = "vega"
-    args = ["start", "--home", "{{.VegaHome}}", "--tendermint-home", "{{.TendermintHome}}"]
+    args = ["start", "--home", "{{.VegaHome}}", "--tendermint-home", "{{.TendermintHome}}"{{if .NodeWalletPassphraseFile}}, "--nodewallet-passphrase-file", "{{.NodeWalletPassphraseFile}}"{{end}}]
   [vega.rpc]
     socketPath = "/tmp/vega.sock"
     httpPath = "/rpc"
-
+{{if .WithDataNode}}
 [data_node]
   [data_node.binary]
     path = "vega"
-    args = ["datanode", "start", "--home", "{{.VegaHome}}"]`
+    args = ["datanode", "start", "--home", "{{.VegaHome}}"]{{end}}`
+
+type VisorRunConfig struct {
+	Version                  string
+	VegaHome                 string
+	TendermintHome           string
+	NodeWalletPassphraseFile string
+	WithDataNode             bool
+}
 
 func InitVisor(binaryPath, visorHome string) error {
 	_, err := utils.ExecuteBinary(binaryPath, []string{"init", "--home", visorHome}, nil)
@@ -32,18 +40,10 @@ func InitVisor(binaryPath, visorHome string) error {
 	return nil
 }
 
-func TemplateVisorRunConfig(version, vegaHome, tendermintHome string) (string, error) {
+func TemplateVisorRunConfig(runConfig VisorRunConfig) (string, error) {
 	tmpl := template.Must(template.New("run-config.toml").Parse(VisorRunConfigTemplate))
 	var buff bytes.Buffer
-	if err := tmpl.Execute(&buff, struct {
-		Version        string
-		VegaHome       string
-		TendermintHome string
-	}{
-		Version:        version,
-		VegaHome:       vegaHome,
-		TendermintHome: tendermintHome,
-	}); err != nil {
+	if err := tmpl.Execute(&buff, runConfig); err != nil {
 		return "", fmt.Errorf("failed to template run-config.toml: %w", err)
 	}
 